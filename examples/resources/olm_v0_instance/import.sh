@@ -0,0 +1,5 @@
+# OLM installed in the default "olm" namespace
+terraform import olm_v0_instance.olm olm
+
+# OLM installed in "olm-system" with its global OperatorGroup in "olm-operators"
+terraform import olm_v0_instance.olm olm-system/olm-operators
@@ -0,0 +1,25 @@
+package olm
+
+import (
+	_ "embed"
+	"strings"
+)
+
+// checksums lists the sha256 checksums of the manifests of vetted OLM
+// releases in sha256sum format, keyed by "<version>/<file>" without a "v"
+// prefix. New releases are added once their manifests have been reviewed.
+//
+//go:embed checksums.sha256
+var checksums string
+
+// Checksum returns the hex encoded sha256 checksum of the manifest file,
+// crds.yaml or olm.yaml, of the OLM release version.
+func Checksum(version, file string) (string, bool) {
+	for _, line := range strings.Split(checksums, "\n") {
+		sum, name, ok := strings.Cut(strings.TrimSpace(line), "  ")
+		if ok && name == version+"/"+file {
+			return sum, true
+		}
+	}
+	return "", false
+}
@@ -14,6 +14,8 @@
 
 package olm
 
+import "sort"
+
 var availableVersions = map[string]struct{}{
 	"0.24.0": {},
 	"0.25.0": {},
@@ -25,3 +27,13 @@ func HasVersion(version string) bool {
 	_, ok := availableVersions[version]
 	return ok
 }
+
+// Versions returns the versions of the OLM manifests stored as bindata, sorted.
+func Versions() []string {
+	versions := make([]string, 0, len(availableVersions))
+	for v := range availableVersions {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
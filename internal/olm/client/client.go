@@ -88,6 +88,28 @@ func init() {
 
 type Client struct {
 	KubeClient client.Client
+	// Log receives progress messages, defaults to a ConsoleLogger.
+	Log Logger
+}
+
+var (
+	schemeOnce sync.Once
+	schemeErr  error
+)
+
+// addToScheme registers the OLM API types with Scheme. The scheme is shared
+// and not safe for concurrent writes, so this only happens once.
+func addToScheme() error {
+	schemeOnce.Do(func() {
+		if err := olmapiv1alpha1.AddToScheme(Scheme); err != nil {
+			schemeErr = fmt.Errorf("failed to add OLM API v1alpha1 types to scheme: %v", err)
+			return
+		}
+		if err := olmapiv1.AddToScheme(Scheme); err != nil {
+			schemeErr = fmt.Errorf("failed to add OLM API v1 types to scheme: %v", err)
+		}
+	})
+	return schemeErr
 }
 
 func NewClientForConfig(cfg *rest.Config, httpClient *http.Client) (*Client, error) {
@@ -96,12 +118,8 @@ func NewClientForConfig(cfg *rest.Config, httpClient *http.Client) (*Client, err
 		return nil, fmt.Errorf("failed to create dynamic rest mapper: %v", err)
 	}
 
-	if err := olmapiv1alpha1.AddToScheme(Scheme); err != nil {
-		return nil, fmt.Errorf("failed to add OLM API v1alpha1 types to scheme: %v", err)
-	}
-
-	if err := olmapiv1.AddToScheme(Scheme); err != nil {
-		return nil, fmt.Errorf("failed to add OLM API v1 types to scheme: %v", err)
+	if err := addToScheme(); err != nil {
+		return nil, err
 	}
 
 	cl, err := client.New(cfg, client.Options{
@@ -120,13 +138,11 @@ func NewClientForConfig(cfg *rest.Config, httpClient *http.Client) (*Client, err
 
 func (c Client) DoCreate(ctx context.Context, objs ...client.Object) error {
 	for _, obj := range objs {
-		kind := obj.GetObjectKind().GroupVersionKind().Kind
-		resourceName := getName(obj.GetNamespace(), obj.GetName())
+		c.Logger().Info(ctx, "Creating resource", ObjectFields(obj))
 
-		log.Infof("  Creating %s %q", kind, resourceName)
-
-		if err := c.safeCreateOneResource(ctx, obj, kind, resourceName); err != nil {
-			log.Infof("  failed to create %s %q; %v", kind, resourceName, err)
+		if err := c.safeCreateOneResource(ctx, obj); err != nil {
+			c.Logger().Warn(ctx, "Failed to create resource", ObjectFields(obj),
+				map[string]interface{}{"error": err.Error()})
 			return err
 		}
 	}
@@ -135,16 +151,16 @@ func (c Client) DoCreate(ctx context.Context, objs ...client.Object) error {
 
 // try to create resource until context is cancelled
 // or resource is created successfully.
-func (c Client) safeCreateOneResource(ctx context.Context, obj client.Object, kind string, resourceName string) error {
+func (c Client) safeCreateOneResource(ctx context.Context, obj client.Object) error {
 	err := wait.PollUntilContextCancel(ctx, time.Second, false, func(ctx context.Context) (bool, error) {
 		err := c.KubeClient.Create(ctx, obj)
 		if err == nil || apierrors.IsAlreadyExists(err) {
-			log.Infof("  %s %q created", kind, resourceName)
+			c.Logger().Debug(ctx, "Resource created", ObjectFields(obj))
 			return true, nil
 		}
 
 		if meta.IsNoMatchError(err) {
-			log.Infof("    Failed to create %s %q. CRD is not ready yet. Retrying...", kind, resourceName)
+			c.Logger().Debug(ctx, "CRD is not ready yet, retrying resource creation", ObjectFields(obj))
 			return false, nil
 		}
 
@@ -158,16 +174,43 @@ func (c Client) safeCreateOneResource(ctx context.Context, obj client.Object, ki
 	return err
 }
 
+// FieldOwner is the field manager of the objects applied by DoApply.
+const FieldOwner = "terraform-provider-olm"
+
+// DoApply creates or updates objs with server-side apply, taking over the
+// fields set by earlier installs or other managers.
+func (c Client) DoApply(ctx context.Context, objs ...client.Object) error {
+	for _, obj := range objs {
+		c.Logger().Info(ctx, "Applying resource", ObjectFields(obj))
+		err := wait.PollUntilContextCancel(ctx, time.Second, false, func(ctx context.Context) (bool, error) {
+			err := c.KubeClient.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldOwner), client.ForceOwnership)
+			if err == nil {
+				return true, nil
+			}
+			if meta.IsNoMatchError(err) {
+				c.Logger().Debug(ctx, "CRD is not ready yet, retrying resource apply", ObjectFields(obj))
+				return false, nil
+			}
+			return false, err
+		})
+		if err != nil {
+			c.Logger().Warn(ctx, "Failed to apply resource", ObjectFields(obj),
+				map[string]interface{}{"error": err.Error()})
+			return err
+		}
+	}
+	return nil
+}
+
 func (c Client) DoDelete(ctx context.Context, objs ...client.Object) error {
 	for _, obj := range objs {
-		kind := obj.GetObjectKind().GroupVersionKind().Kind
-		log.Infof("  Deleting %s %q", kind, getName(obj.GetNamespace(), obj.GetName()))
+		c.Logger().Info(ctx, "Deleting resource", ObjectFields(obj))
 		err := c.KubeClient.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
 		if err != nil {
 			if !apierrors.IsNotFound(err) {
 				return err
 			}
-			log.Infof("    %s %q does not exist", kind, getName(obj.GetNamespace(), obj.GetName()))
+			c.Logger().Debug(ctx, "Resource does not exist", ObjectFields(obj))
 		}
 		key := client.ObjectKeyFromObject(obj)
 		if err := wait.PollUntilContextCancel(ctx, time.Millisecond*100, false, func(pctx context.Context) (bool, error) {
@@ -185,13 +228,6 @@ func (c Client) DoDelete(ctx context.Context, objs ...client.Object) error {
 	return nil
 }
 
-func getName(namespace, name string) string {
-	if namespace != "" {
-		name = fmt.Sprintf("%s/%s", namespace, name)
-	}
-	return name
-}
-
 func (c Client) DoRolloutWait(ctx context.Context, key types.NamespacedName) error {
 	onceNotFound := sync.Once{}
 	onceReplicasUpdated := sync.Once{}
@@ -199,13 +235,14 @@ func (c Client) DoRolloutWait(ctx context.Context, key types.NamespacedName) err
 	onceNotAvailable := sync.Once{}
 	onceSpecUpdate := sync.Once{}
 
+	fields := KeyFields("Deployment", key)
 	rolloutComplete := func(pctx context.Context) (bool, error) {
 		deployment := appsv1.Deployment{}
 		err := c.KubeClient.Get(pctx, key, &deployment)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				onceNotFound.Do(func() {
-					log.Printf("  Waiting for Deployment %q to appear", key)
+					c.Logger().Info(pctx, "Waiting for Deployment to appear", fields)
 				})
 				return false, nil
 			}
@@ -218,32 +255,39 @@ func (c Client) DoRolloutWait(ctx context.Context, key types.NamespacedName) err
 			}
 			if deployment.Spec.Replicas != nil && deployment.Status.UpdatedReplicas < *deployment.Spec.Replicas {
 				onceReplicasUpdated.Do(func() {
-					log.Printf(
-						"  Waiting for Deployment %q to rollout: %d out of %d new replicas have been updated",
-						key, deployment.Status.UpdatedReplicas, *deployment.Spec.Replicas)
+					c.Logger().Info(pctx, "Waiting for Deployment to rollout: new replicas are being updated", fields,
+						map[string]interface{}{
+							"updated_replicas": deployment.Status.UpdatedReplicas,
+							"replicas":         *deployment.Spec.Replicas,
+						})
 				})
 				return false, nil
 			}
 			if deployment.Status.Replicas > deployment.Status.UpdatedReplicas {
 				oncePendingTermination.Do(func() {
-					log.Printf("  Waiting for Deployment %q to rollout: %d old replicas are pending termination",
-						key, deployment.Status.Replicas-deployment.Status.UpdatedReplicas)
+					c.Logger().Info(pctx, "Waiting for Deployment to rollout: old replicas are pending termination", fields,
+						map[string]interface{}{
+							"pending_termination": deployment.Status.Replicas - deployment.Status.UpdatedReplicas,
+						})
 				})
 				return false, nil
 			}
 			if deployment.Status.AvailableReplicas < deployment.Status.UpdatedReplicas {
 				onceNotAvailable.Do(func() {
-					log.Printf("  Waiting for Deployment %q to rollout: %d of %d updated replicas are available",
-						key, deployment.Status.AvailableReplicas, deployment.Status.UpdatedReplicas)
+					c.Logger().Info(pctx, "Waiting for Deployment to rollout: updated replicas are becoming available", fields,
+						map[string]interface{}{
+							"available_replicas": deployment.Status.AvailableReplicas,
+							"updated_replicas":   deployment.Status.UpdatedReplicas,
+						})
 				})
 				return false, nil
 			}
-			log.Printf("  Deployment %q successfully rolled out", key)
+			c.Logger().Info(pctx, "Deployment successfully rolled out", fields)
 			return true, nil
 		}
 		onceSpecUpdate.Do(func() {
-			log.Printf("Waiting for Deployment %q to rollout: waiting for deployment spec update to be observed",
-				key)
+			c.Logger().Info(pctx, "Waiting for Deployment to rollout: waiting for deployment spec update to be observed",
+				fields)
 		})
 		return false, nil
 	}
@@ -257,13 +301,14 @@ func (c Client) DoCSVWait(ctx context.Context, key types.NamespacedName) error {
 	)
 	once := sync.Once{}
 
+	fields := KeyFields(olmapiv1alpha1.ClusterServiceVersionKind, key)
 	csv := olmapiv1alpha1.ClusterServiceVersion{}
 	csvPhaseSucceeded := func(pctx context.Context) (bool, error) {
 		err := c.KubeClient.Get(pctx, key, &csv)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				once.Do(func() {
-					log.Printf("  Waiting for ClusterServiceVersion %q to appear", key)
+					c.Logger().Info(pctx, "Waiting for ClusterServiceVersion to appear", fields)
 				})
 				return false, nil
 			}
@@ -272,7 +317,8 @@ func (c Client) DoCSVWait(ctx context.Context, key types.NamespacedName) error {
 		newPhase = csv.Status.Phase
 		if newPhase != curPhase {
 			curPhase = newPhase
-			log.Printf("  Found ClusterServiceVersion %q phase: %s", key, curPhase)
+			c.Logger().Info(pctx, "Found ClusterServiceVersion phase", fields,
+				map[string]interface{}{"phase": string(curPhase)})
 		}
 
 		switch curPhase {
@@ -376,13 +422,36 @@ func (c Client) checkPodErrors(ctx context.Context, depSelectors *metav1.LabelSe
 
 // GetInstalledVersion returns the OLM version installed in the namespace informed.
 func (c Client) GetInstalledVersion(ctx context.Context, namespace string) (string, error) {
-	opts := client.InNamespace(namespace)
+	csv, err := c.getPackageServerCSV(ctx, client.InNamespace(namespace))
+	if err != nil {
+		return "", err
+	}
+	return getOLMVersionFromPackageServerCSV(csv)
+}
+
+// FindInstalledVersion returns the namespace and the version of the OLM
+// installation, looking in namespace first and then in the whole cluster, so
+// that an installation moved out of band is still found.
+func (c Client) FindInstalledVersion(ctx context.Context, namespace string) (string, string, error) {
+	csv, err := c.getPackageServerCSV(ctx, client.InNamespace(namespace))
+	if errors.Is(err, ErrOLMNotInstalled) {
+		csv, err = c.getPackageServerCSV(ctx)
+	}
+	if err != nil {
+		return "", "", err
+	}
+	version, err := getOLMVersionFromPackageServerCSV(csv)
+	return csv.GetNamespace(), version, err
+}
+
+// getPackageServerCSV returns the only packageserver CSV matched by opts.
+func (c Client) getPackageServerCSV(ctx context.Context, opts ...client.ListOption) (*olmapiv1alpha1.ClusterServiceVersion, error) {
 	csvs := &olmapiv1alpha1.ClusterServiceVersionList{}
-	if err := c.KubeClient.List(ctx, csvs, opts); err != nil {
+	if err := c.KubeClient.List(ctx, csvs, opts...); err != nil {
 		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
-			return "", ErrOLMNotInstalled
+			return nil, ErrOLMNotInstalled
 		}
-		return "", fmt.Errorf("failed to list CSVs in namespace %q: %v", namespace, err)
+		return nil, fmt.Errorf("failed to list CSVs: %v", err)
 	}
 	var pkgServerCSV *olmapiv1alpha1.ClusterServiceVersion
 	for i := range csvs.Items {
@@ -393,16 +462,16 @@ func (c Client) GetInstalledVersion(ctx context.Context, namespace string) (stri
 			// There is more than one version of OLM installed in the cluster,
 			// so we can't resolve the version being used.
 			if pkgServerCSV != nil {
-				return "", fmt.Errorf("more than one OLM (package server) version installed: %q and %q",
-					pkgServerCSV.GetName(), name)
+				return nil, fmt.Errorf("more than one OLM (package server) version installed: %q and %q",
+					pkgServerCSV.GetNamespace()+"/"+pkgServerCSV.GetName(), csv.GetNamespace()+"/"+name)
 			}
 			pkgServerCSV = &csv
 		}
 	}
 	if pkgServerCSV == nil {
-		return "", ErrOLMNotInstalled
+		return nil, ErrOLMNotInstalled
 	}
-	return getOLMVersionFromPackageServerCSV(pkgServerCSV)
+	return pkgServerCSV, nil
 }
 
 const (
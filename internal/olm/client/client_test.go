@@ -349,6 +349,45 @@ var _ = Describe("Client", func() {
 
 var _ client.Client = &errClient{}
 
+var _ = Describe("FindInstalledVersion", func() {
+	packageServer := func(namespace, version string) *olmapiv1alpha1.ClusterServiceVersion {
+		return &olmapiv1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{
+			Name:      pkgServerCSVNewName,
+			Namespace: namespace,
+			Labels:    map[string]string{pkgServerOLMVersionLabel: version},
+		}}
+	}
+	find := func(objs ...client.Object) (string, string, error) {
+		c := Client{KubeClient: fake.NewClientBuilder().WithScheme(Scheme).WithObjects(objs...).Build()}
+		return c.FindInstalledVersion(context.TODO(), "olm")
+	}
+
+	It("finds OLM in the namespace", func() {
+		namespace, version, err := find(packageServer("olm", "0.26.0"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(namespace).To(Equal("olm"))
+		Expect(version).To(Equal("0.26.0"))
+	})
+
+	It("finds OLM moved to another namespace", func() {
+		namespace, version, err := find(packageServer("olm-system", "0.27.0"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(namespace).To(Equal("olm-system"))
+		Expect(version).To(Equal("0.27.0"))
+	})
+
+	It("prefers the namespace over other installations", func() {
+		namespace, _, err := find(packageServer("olm", "0.26.0"), packageServer("olm-system", "0.27.0"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(namespace).To(Equal("olm"))
+	})
+
+	It("reports a missing installation", func() {
+		_, _, err := find()
+		Expect(err).To(MatchError(ErrOLMNotInstalled))
+	})
+})
+
 type errClient struct {
 	cli            client.Client
 	noMatchCounter int
@@ -0,0 +1,83 @@
+// Copyright 2019 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Logger receives the progress of OLM operations. Fields carry structured
+// context such as the resource, namespace, GVK or phase.
+type Logger interface {
+	Debug(ctx context.Context, msg string, fields ...map[string]interface{})
+	Info(ctx context.Context, msg string, fields ...map[string]interface{})
+	Warn(ctx context.Context, msg string, fields ...map[string]interface{})
+}
+
+// ConsoleLogger writes to the console through logrus. It's used when no
+// other Logger is set on the Client.
+type ConsoleLogger struct{}
+
+func (ConsoleLogger) Debug(_ context.Context, msg string, fields ...map[string]interface{}) {
+	log.WithFields(mergeFields(fields)).Debug(msg)
+}
+
+func (ConsoleLogger) Info(_ context.Context, msg string, fields ...map[string]interface{}) {
+	log.WithFields(mergeFields(fields)).Info(msg)
+}
+
+func (ConsoleLogger) Warn(_ context.Context, msg string, fields ...map[string]interface{}) {
+	log.WithFields(mergeFields(fields)).Warn(msg)
+}
+
+func mergeFields(fields []map[string]interface{}) log.Fields {
+	merged := log.Fields{}
+	for _, f := range fields {
+		for k, v := range f {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// Logger returns the Logger of c, falling back to a ConsoleLogger.
+func (c Client) Logger() Logger {
+	if c.Log == nil {
+		return ConsoleLogger{}
+	}
+	return c.Log
+}
+
+// ObjectFields returns the log fields identifying obj.
+func ObjectFields(obj client.Object) map[string]interface{} {
+	return map[string]interface{}{
+		"gvk":       obj.GetObjectKind().GroupVersionKind().String(),
+		"namespace": obj.GetNamespace(),
+		"name":      obj.GetName(),
+	}
+}
+
+// KeyFields returns the log fields identifying the resource of kind at key.
+func KeyFields(kind string, key types.NamespacedName) map[string]interface{} {
+	return map[string]interface{}{
+		"resource":  kind,
+		"namespace": key.Namespace,
+		"name":      key.Name,
+	}
+}
@@ -164,3 +164,16 @@ func (s Status) String() string {
 
 	return out.String()
 }
+
+// MissingResources returns the statuses of the resources in s that were not
+// found on the cluster, including custom resources whose CRD is missing.
+// Resources that could not be checked for other reasons are not included.
+func (s Status) MissingResources() []ResourceStatus {
+	var missing []ResourceStatus
+	for _, r := range s.Resources {
+		if r.Resource == nil && (apierrors.IsNotFound(r.Error) || meta.IsNoMatchError(r.Error)) {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
@@ -0,0 +1,42 @@
+package client
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Status", func() {
+	Describe("MissingResources", func() {
+		resourceStatus := func(name string, err error) ResourceStatus {
+			rs := ResourceStatus{NamespacedName: types.NamespacedName{Namespace: "olm", Name: name}, Error: err}
+			if err == nil {
+				rs.Resource = &unstructured.Unstructured{}
+			}
+			return rs
+		}
+
+		It("returns the resources that were not found", func() {
+			s := Status{Resources: []ResourceStatus{
+				resourceStatus("installed", nil),
+				resourceStatus("deleted", apierrors.NewNotFound(schema.GroupResource{Resource: "deployments"}, "deleted")),
+				resourceStatus("no-crd", &meta.NoKindMatchError{GroupKind: schema.GroupKind{Kind: "CatalogSource"}}),
+				resourceStatus("forbidden", apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "forbidden", nil)),
+			}}
+			var names []string
+			for _, r := range s.MissingResources() {
+				names = append(names, r.NamespacedName.Name)
+			}
+			Expect(names).To(Equal([]string{"deleted", "no-crd"}))
+		})
+
+		It("returns nothing for a complete installation", func() {
+			s := Status{Resources: []ResourceStatus{resourceStatus("installed", nil)}}
+			Expect(s.MissingResources()).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,167 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+const (
+	// ManagedByLabel marks the objects of an OLM installation managed by the provider.
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	// ManagedByValue is the value of ManagedByLabel on the objects managed by the provider.
+	ManagedByValue = olmresourceclient.FieldOwner
+)
+
+// labelManaged sets ManagedByLabel on every resource.
+func labelManaged(resources []unstructured.Unstructured) {
+	for i := range resources {
+		labels := resources[i].GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[ManagedByLabel] = ManagedByValue
+		resources[i].SetLabels(labels)
+	}
+}
+
+// verifyAdoptable checks that the OLM objects already on the cluster are the
+// ones of the version in opts, so they can be taken over instead of installed.
+// Objects missing from the cluster are fine, they're created on adoption. The
+// returned error lists every field of a live object that differs from the
+// manifests.
+func (c Client) verifyAdoptable(ctx context.Context, opts InstallOptions, resources []unstructured.Unstructured) error {
+	version, err := c.GetInstalledVersion(ctx, opts.olmNamespace())
+	if err == nil && !SameVersion(version, opts.Version) {
+		return fmt.Errorf("cannot adopt the existing OLM installation: version %s is installed, not %s",
+			version, opts.Version)
+	} else if err != nil && !errors.Is(err, olmresourceclient.ErrOLMNotInstalled) {
+		return fmt.Errorf("cannot adopt the existing OLM installation: %v", err)
+	}
+
+	var report []string
+	for _, desired := range resources {
+		live := unstructured.Unstructured{}
+		live.SetGroupVersionKind(desired.GroupVersionKind())
+		err := c.KubeClient.Get(ctx, objectKey(desired), &live)
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		} else if err != nil {
+			return fmt.Errorf("failed to get %s: %v", resourceName(desired), err)
+		}
+		for _, mismatch := range diffResource(desired, live) {
+			report = append(report, resourceName(desired)+": "+mismatch)
+		}
+	}
+	if len(report) > 0 {
+		return fmt.Errorf("cannot adopt the existing OLM installation, it doesn't match version %s:\n  %s",
+			opts.Version, strings.Join(report, "\n  "))
+	}
+	return nil
+}
+
+// diffResource returns the fields set by the desired manifest that have
+// another value in the live object. Fields the API server defaults are not
+// compared, nor are metadata and status.
+func diffResource(desired, live unstructured.Unstructured) []string {
+	var mismatches []string
+	fields := make([]string, 0, len(desired.Object))
+	for field := range desired.Object {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		switch field {
+		case "apiVersion", "kind", "metadata", "status":
+			continue
+		}
+		mismatches = append(mismatches, diffValue(field, desired.Object[field], live.Object[field])...)
+	}
+	return mismatches
+}
+
+func diffValue(path string, desired, live interface{}) []string {
+	switch d := desired.(type) {
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			return []string{fmt.Sprintf("%s: expected an object, found %s", path, formatValue(live))}
+		}
+		keys := make([]string, 0, len(d))
+		for key := range d {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		var mismatches []string
+		for _, key := range keys {
+			mismatches = append(mismatches, diffValue(path+"."+key, d[key], l[key])...)
+		}
+		return mismatches
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok || len(l) != len(d) {
+			return []string{fmt.Sprintf("%s: expected %d items, found %s", path, len(d), formatValue(live))}
+		}
+		var mismatches []string
+		for i := range d {
+			mismatches = append(mismatches, diffValue(fmt.Sprintf("%s[%d]", path, i), d[i], l[i])...)
+		}
+		return mismatches
+	default:
+		if df, ok := toFloat(desired); ok {
+			if lf, ok := toFloat(live); ok && df == lf {
+				return nil
+			}
+		}
+		if !reflect.DeepEqual(desired, live) {
+			return []string{fmt.Sprintf("%s: expected %s, found %s", path, formatValue(desired), formatValue(live))}
+		}
+		return nil
+	}
+}
+
+// toFloat converts the number types unstructured objects hold to float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "nothing"
+	case map[string]interface{}:
+		return "an object"
+	case []interface{}:
+		return fmt.Sprintf("%d items", len(v))
+	default:
+		return fmt.Sprintf("%q", fmt.Sprint(v))
+	}
+}
+
+func objectKey(r unstructured.Unstructured) types.NamespacedName {
+	return types.NamespacedName{Namespace: r.GetNamespace(), Name: r.GetName()}
+}
+
+// resourceName names r in messages, e.g. "Deployment olm/olm-operator".
+func resourceName(r unstructured.Unstructured) string {
+	return r.GetKind() + " " + objectKey(r).String()
+}
@@ -0,0 +1,95 @@
+package installer
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+var _ = Describe("diffResource", func() {
+	var desired, live unstructured.Unstructured
+
+	BeforeEach(func() {
+		resources, err := getPackagedManifests(filepath.Join(bindataManifestPath, "0.26.0-olm.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		for _, r := range resources {
+			if r.GetKind() == "Deployment" && r.GetName() == olmOperatorName {
+				desired = r
+			}
+		}
+		Expect(desired.Object).NotTo(BeNil())
+		live = *desired.DeepCopy()
+	})
+
+	It("accepts an identical object", func() {
+		Expect(diffResource(desired, live)).To(BeEmpty())
+	})
+
+	It("ignores metadata, status and fields defaulted by the API server", func() {
+		live.SetLabels(map[string]string{"installed-by": "operator-sdk"})
+		live.SetResourceVersion("42")
+		Expect(unstructured.SetNestedField(live.Object, int64(1), "status", "replicas")).To(Succeed())
+		Expect(unstructured.SetNestedField(live.Object, int64(600), "spec", "progressDeadlineSeconds")).To(Succeed())
+		Expect(diffResource(desired, live)).To(BeEmpty())
+	})
+
+	It("compares numbers regardless of their type", func() {
+		Expect(unstructured.SetNestedField(desired.Object, int64(1), "spec", "replicas")).To(Succeed())
+		Expect(unstructured.SetNestedField(live.Object, float64(1), "spec", "replicas")).To(Succeed())
+		Expect(diffResource(desired, live)).To(BeEmpty())
+	})
+
+	It("reports every field that differs", func() {
+		containers, _, err := unstructured.NestedSlice(live.Object, "spec", "template", "spec", "containers")
+		Expect(err).NotTo(HaveOccurred())
+		container := containers[0].(map[string]interface{})
+		container["image"] = "quay.io/operator-framework/olm:v0.25.0"
+		container["args"] = []interface{}{"--namespace", "olm"}
+		Expect(unstructured.SetNestedSlice(live.Object, containers, "spec", "template", "spec", "containers")).To(Succeed())
+		Expect(unstructured.SetNestedField(live.Object, "Recreate", "spec", "strategy", "type")).To(Succeed())
+
+		mismatches := diffResource(desired, live)
+		Expect(mismatches).To(HaveLen(3))
+		Expect(mismatches).To(ContainElement(HavePrefix("spec.strategy.type: expected")))
+		Expect(mismatches).To(ContainElement(HavePrefix("spec.template.spec.containers[0].args: expected")))
+		Expect(mismatches).To(ContainElement(
+			`spec.template.spec.containers[0].image: expected "` + container0Image(desired) +
+				`", found "quay.io/operator-framework/olm:v0.25.0"`))
+	})
+})
+
+var _ = Describe("labelManaged", func() {
+	It("labels every resource and keeps the existing labels", func() {
+		resources, err := getPackagedManifests(filepath.Join(bindataManifestPath, "0.26.0-olm.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		labelManaged(resources)
+		for _, r := range resources {
+			Expect(r.GetLabels()).To(HaveKeyWithValue(ManagedByLabel, ManagedByValue), resourceName(r))
+		}
+		for _, r := range resources {
+			if r.GetKind() == "ClusterServiceVersion" {
+				Expect(r.GetLabels()).To(HaveKeyWithValue("olm.version", "v0.26.0"))
+			}
+		}
+	})
+
+	It("labels the CRDs of an installation", func() {
+		c := Client{Client: &olmresourceclient.Client{}}
+		crds, resources, err := c.getResources(context.TODO(), InstallOptions{Version: "0.26.0"})
+		Expect(err).NotTo(HaveOccurred())
+		for _, r := range append(crds, resources...) {
+			Expect(r.GetLabels()).To(HaveKeyWithValue(ManagedByLabel, ManagedByValue), resourceName(r))
+		}
+	})
+})
+
+func container0Image(deployment unstructured.Unstructured) string {
+	containers, _, _ := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+	image, _, _ := unstructured.NestedString(containers[0].(map[string]interface{}), "image")
+	return image
+}
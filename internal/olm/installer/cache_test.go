@@ -0,0 +1,87 @@
+package installer
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+var _ = Describe("manifest cache", func() {
+	var (
+		c         Client
+		server    *httptest.Server
+		downloads atomic.Int32
+		opts      InstallOptions
+	)
+
+	BeforeEach(func() {
+		downloads.Store(0)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			downloads.Add(1)
+			_, _ = w.Write([]byte(testManifest))
+		}))
+		c = Client{
+			Client:          &olmresourceclient.Client{},
+			HTTPClient:      *server.Client(),
+			BaseDownloadURL: server.URL,
+			CacheDir:        GinkgoT().TempDir(),
+		}
+		opts = InstallOptions{
+			Version:   "0.99.0",
+			Checksums: ManifestChecksums{CRDs: testManifestChecksum(), OLM: "sha256:" + testManifestChecksum()},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("serves verified downloads from the cache", func() {
+		_, _, err := c.fetchManifests(context.TODO(), opts)
+		Expect(err).NotTo(HaveOccurred())
+		// Both manifests have the same content, the second one is already cached
+		Expect(downloads.Load()).To(BeEquivalentTo(1))
+		Expect(filepath.Join(c.CacheDir, "sha256", testManifestChecksum())).To(BeARegularFile())
+
+		server.Close()
+		_, resources, err := c.fetchManifests(context.TODO(), opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(HaveLen(2))
+		Expect(downloads.Load()).To(BeEquivalentTo(1))
+	})
+
+	It("doesn't cache manifests that fail verification", func() {
+		opts.Checksums.OLM = "sha256:" + testManifestChecksum()[1:] + "0"
+		_, _, err := c.fetchManifests(context.TODO(), opts)
+		Expect(err).To(MatchError(ContainSubstring("failed verification")))
+		Expect(filepath.Join(c.CacheDir, "sha256", opts.Checksums.OLM[len("sha256:"):])).NotTo(BeAnExistingFile())
+	})
+
+	It("downloads again when a cached manifest is corrupted", func() {
+		cached := filepath.Join(c.CacheDir, "sha256", testManifestChecksum())
+		Expect(os.MkdirAll(filepath.Dir(cached), 0o700)).To(Succeed())
+		Expect(os.WriteFile(cached, []byte("corrupted"), 0o600)).To(Succeed())
+
+		_, _, err := c.fetchManifests(context.TODO(), opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(downloads.Load()).To(BeEquivalentTo(1))
+		Expect(os.ReadFile(cached)).To(BeEquivalentTo(testManifest))
+	})
+
+	It("is skipped without a cache directory", func() {
+		c.CacheDir = ""
+		_, _, err := c.fetchManifests(context.TODO(), opts)
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = c.fetchManifests(context.TODO(), opts)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(downloads.Load()).To(BeEquivalentTo(4))
+	})
+})
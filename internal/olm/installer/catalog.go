@@ -0,0 +1,65 @@
+package installer
+
+import (
+	"fmt"
+
+	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// DefaultCatalogName is the name of the CatalogSource OLM is released with,
+// the operatorhub.io community catalog.
+const DefaultCatalogName = "operatorhubio-catalog"
+
+// CatalogOptions customizes the default CatalogSource. The zero value keeps
+// the upstream catalog.
+type CatalogOptions struct {
+	// Disabled leaves the default catalog out of the installation.
+	Disabled bool
+	// Image replaces the catalog image, e.g. with a mirror of the catalog.
+	Image string
+	// UpdateInterval replaces how often the catalog image is polled for
+	// updates, as a duration such as "60m".
+	UpdateInterval string
+	// DisplayName replaces the display name of the catalog.
+	DisplayName string
+	// Publisher replaces the publisher of the catalog.
+	Publisher string
+}
+
+// customizeCatalog applies opts to the default CatalogSource in resources and
+// returns the resulting resources, without the catalog when it's disabled.
+func customizeCatalog(resources []unstructured.Unstructured, opts CatalogOptions) ([]unstructured.Unstructured, error) {
+	isCatalog := func(r unstructured.Unstructured) bool {
+		return r.GetKind() == olmapiv1alpha1.CatalogSourceKind && r.GetName() == DefaultCatalogName
+	}
+	if opts.Disabled {
+		return filterResources(resources, func(r unstructured.Unstructured) bool {
+			return !isCatalog(r)
+		}), nil
+	}
+
+	for i := range resources {
+		r := &resources[i]
+		if !isCatalog(*r) {
+			continue
+		}
+		for _, field := range []struct {
+			value string
+			path  []string
+		}{
+			{opts.Image, []string{"spec", "image"}},
+			{opts.UpdateInterval, []string{"spec", "updateStrategy", "registryPoll", "interval"}},
+			{opts.DisplayName, []string{"spec", "displayName"}},
+			{opts.Publisher, []string{"spec", "publisher"}},
+		} {
+			if field.value == "" {
+				continue
+			}
+			if err := unstructured.SetNestedField(r.Object, field.value, field.path...); err != nil {
+				return nil, fmt.Errorf("failed to customize CatalogSource %q: %v", r.GetName(), err)
+			}
+		}
+	}
+	return resources, nil
+}
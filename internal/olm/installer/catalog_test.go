@@ -0,0 +1,61 @@
+package installer
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("customizeCatalog", func() {
+	var resources []unstructured.Unstructured
+
+	BeforeEach(func() {
+		var err error
+		resources, err = getPackagedManifests(filepath.Join(bindataManifestPath, "0.26.0-olm.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	catalogs := func(resources []unstructured.Unstructured) []unstructured.Unstructured {
+		return filterResources(resources, func(r unstructured.Unstructured) bool {
+			return r.GetKind() == "CatalogSource"
+		})
+	}
+
+	It("keeps the upstream catalog by default", func() {
+		customized, err := customizeCatalog(resources, CatalogOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(customized).To(Equal(resources))
+		Expect(catalogs(customized)).To(HaveLen(1))
+	})
+
+	It("leaves out a disabled catalog", func() {
+		customized, err := customizeCatalog(resources, CatalogOptions{Disabled: true, Image: "ignored"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(customized).To(HaveLen(len(resources) - 1))
+		Expect(catalogs(customized)).To(BeEmpty())
+	})
+
+	It("customizes the catalog", func() {
+		customized, err := customizeCatalog(resources, CatalogOptions{
+			Image:          "registry.example.com/operatorhubio/catalog:approved",
+			UpdateInterval: "24h",
+			DisplayName:    "Approved Operators",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		catalog := catalogs(customized)[0]
+		Expect(catalog.Object["spec"]).To(Equal(map[string]interface{}{
+			"sourceType":  "grpc",
+			"image":       "registry.example.com/operatorhubio/catalog:approved",
+			"displayName": "Approved Operators",
+			"publisher":   "OperatorHub.io",
+			"grpcPodConfig": map[string]interface{}{
+				"securityContextConfig": "restricted",
+			},
+			"updateStrategy": map[string]interface{}{
+				"registryPoll": map[string]interface{}{"interval": "24h"},
+			},
+		}))
+	})
+})
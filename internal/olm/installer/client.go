@@ -20,18 +20,20 @@ package installer
 import (
 	"bytes"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"path/filepath"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/blang/semver/v4"
 	olmmanifests "github.com/kaplan-michael/terraform-provider-olm/internal/bindata/olm"
 	olmapiv1 "github.com/operator-framework/api/pkg/operators/v1"
 	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
-	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -48,12 +50,56 @@ const (
 	catalogOperatorName = "catalog-operator"
 	packageServerName   = "packageserver"
 	bindataManifestPath = "olm-manifests"
+
+	// DefaultBaseDownloadURL is where manifests of non-bundled OLM versions are downloaded from.
+	DefaultBaseDownloadURL = "https://github.com/operator-framework/operator-lifecycle-manager/releases"
+	// DefaultDownloadRetries is how many times a failed manifest download is retried.
+	DefaultDownloadRetries = 3
 )
 
+// ErrExistingOLM is returned by InstallVersion when OLM objects are already on
+// the cluster and InstallOptions.AdoptExisting is not set.
+var ErrExistingOLM = errors.New(
+	"detected existing OLM resources: OLM must be completely uninstalled before installation")
+
 type Client struct {
 	*olmresourceclient.Client
 	HTTPClient      http.Client
 	BaseDownloadURL string
+	// ReleasesURL lists the releases published at BaseDownloadURL, empty
+	// when they can't be listed.
+	ReleasesURL string
+	// Headers are added to every manifest download request.
+	Headers map[string]string
+	// GitHubToken authorizes the requests sent to the GitHub hosts only.
+	GitHubToken string
+	// Retries is how many times a failed manifest download is retried.
+	Retries int
+	// CacheDir is where verified manifest downloads are cached, empty
+	// disables the cache.
+	CacheDir string
+}
+
+// DownloadOptions configures how the manifests of versions that aren't
+// bundled with the provider are downloaded.
+type DownloadOptions struct {
+	// BaseURL replaces DefaultBaseDownloadURL, e.g. with an internal mirror
+	// of the OLM release assets.
+	BaseURL string
+	// Headers are added to every request, e.g. for authentication.
+	Headers map[string]string
+	// GitHubToken is sent as bearer authorization to github.com and its
+	// API and release asset hosts, never to a mirror.
+	GitHubToken string
+	// CAData is a PEM encoded CA bundle trusted in addition to the system roots.
+	CAData []byte
+	// ProxyURL overrides the proxy from the environment.
+	ProxyURL string
+	// Retries is how many times a failed download is retried.
+	Retries int
+	// CacheDir is where verified downloads are cached, see DefaultCacheDir.
+	// Empty disables the cache.
+	CacheDir string
 }
 
 func ClientForConfig(cfg *rest.Config) (*Client, error) {
@@ -69,29 +115,67 @@ func ClientForConfig(cfg *rest.Config) (*Client, error) {
 	c := &Client{
 		Client:          cl,
 		HTTPClient:      *http.DefaultClient,
-		BaseDownloadURL: "https://github.com/operator-framework/operator-lifecycle-manager/releases",
+		BaseDownloadURL: DefaultBaseDownloadURL,
+		ReleasesURL:     DefaultReleasesURL,
+		Retries:         DefaultDownloadRetries,
 	}
 	return c, nil
 }
 
-func (c Client) InstallVersion(ctx context.Context, namespace, version string) (*olmresourceclient.Status, error) {
-	crds, resources, err := c.getResources(ctx, version)
+// SetDownloadOptions configures the manifest downloads of c.
+func (c *Client) SetDownloadOptions(opts DownloadOptions) error {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if len(opts.CAData) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(opts.CAData) {
+			return errors.New("no valid certificates found in the manifest CA bundle")
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	if opts.ProxyURL != "" {
+		proxyURL, err := url.Parse(opts.ProxyURL)
+		if err != nil {
+			return fmt.Errorf("invalid manifest proxy URL: %v", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	c.HTTPClient = http.Client{Transport: transport}
+	c.BaseDownloadURL, c.ReleasesURL = DefaultBaseDownloadURL, DefaultReleasesURL
+	if opts.BaseURL != "" {
+		c.BaseDownloadURL, c.ReleasesURL = strings.TrimSuffix(opts.BaseURL, "/"), ""
+	}
+	c.Headers = opts.Headers
+	c.GitHubToken = opts.GitHubToken
+	c.Retries = opts.Retries
+	c.CacheDir = opts.CacheDir
+	return nil
+}
+
+func (c Client) InstallVersion(ctx context.Context, opts InstallOptions) (*olmresourceclient.Status, error) {
+	crds, resources, err := c.getResources(ctx, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get resources: %v", err)
+		return nil, fmt.Errorf("failed to get resources: %w", err)
 	}
 
-	log.Info("Checking for existing OLM CRDs")
+	log := c.Logger()
+	namespace := opts.olmNamespace()
+	versionFields := map[string]interface{}{"version": opts.Version, "namespace": namespace}
+
+	log.Info(ctx, "Checking for existing OLM CRDs", versionFields)
 	crdObjs := toObjects(crds...)
 	status := c.GetObjectsStatus(ctx, crdObjs...)
 	crdsInstalled, err := status.HasInstalledResources()
 	if err != nil {
 		return nil, fmt.Errorf("detected errored OLM resources: %v", err)
-	} else if crdsInstalled {
-		return nil, errors.New(
-			"detected existing OLM resources: OLM must be completely uninstalled before installation")
+	} else if crdsInstalled && !opts.AdoptExisting {
+		return nil, ErrExistingOLM
 	}
 
-	log.Info("Checking for existing OLM resources")
+	log.Info(ctx, "Checking for existing OLM resources", versionFields)
 	nonOlmCrds := filterResources(resources, func(r unstructured.Unstructured) bool {
 		return r.GroupVersionKind().GroupVersion() != olmapiv1.GroupVersion && r.GroupVersionKind().GroupVersion() != schema.GroupVersion{
 			Group:   olmapiv1alpha1.GroupName,
@@ -103,13 +187,22 @@ func (c Client) InstallVersion(ctx context.Context, namespace, version string) (
 	installed, err := status.HasInstalledResources()
 	if err != nil {
 		return nil, fmt.Errorf("detected errored OLM resources: %v", err)
-	} else if installed {
-		return nil, errors.New(
-			"detected existing OLM resources: OLM must be completely uninstalled before installation")
+	} else if installed && !opts.AdoptExisting {
+		return nil, ErrExistingOLM
 	}
 
-	log.Info("Installing OLM CRDs...")
-	if err := c.DoCreate(ctx, crdObjs...); err != nil {
+	// Existing objects are taken over by the server-side apply below, which creates the missing ones.
+	if crdsInstalled || installed {
+		log.Info(ctx, "Verifying existing OLM resources before adopting them", versionFields, phaseField("adopt"))
+		if err := c.verifyAdoptable(ctx, opts, append(crds, resources...)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Objects are applied rather than created, so that later updates with
+	// UpgradeVersion own, and can remove, every field set here.
+	log.Info(ctx, "Installing OLM CRDs", versionFields, phaseField("crds"))
+	if err := c.DoApply(ctx, crdObjs...); err != nil {
 		return nil, fmt.Errorf("failed to create CRDs: %v", err)
 	}
 
@@ -119,25 +212,41 @@ func (c Client) InstallVersion(ctx context.Context, namespace, version string) (
 		return status.HasInstalledResources()
 	})
 	if err != nil {
-		return nil, fmt.Errorf("waiting for CRDs to be installed: %v", err)
+		return nil, phaseError(ctx, PhaseCRDs, fmt.Errorf("waiting for CRDs to be installed: %v", err))
 	}
 
-	log.Print("Creating OLM resources...")
+	log.Info(ctx, "Creating OLM resources", versionFields, phaseField("resources"))
 	objs := toObjects(resources...)
-	if err := c.DoCreate(ctx, objs...); err != nil {
+	if err := c.DoApply(ctx, objs...); err != nil {
 		return nil, fmt.Errorf("failed to create CRDs and resources: %v", err)
 	}
 
-	log.Print("Waiting for deployment/olm-operator rollout to complete")
+	if err := c.waitForOLM(ctx, namespace, resources); err != nil {
+		return nil, err
+	}
+
+	objs = toObjects(append(crds, resources...)...)
+	status = c.GetObjectsStatus(ctx, objs...)
+	return &status, nil
+}
+
+// waitForOLM waits for the OLM deployments in namespace to roll out and for
+// the subscriptions among resources to install their CSVs.
+func (c Client) waitForOLM(ctx context.Context, namespace string, resources []unstructured.Unstructured) error {
+	log := c.Logger()
+
 	olmOperatorKey := types.NamespacedName{Namespace: namespace, Name: olmOperatorName}
+	log.Info(ctx, "Waiting for rollout to complete", olmresourceclient.KeyFields("Deployment", olmOperatorKey),
+		phaseField("rollout"))
 	if err := c.DoRolloutWait(ctx, olmOperatorKey); err != nil {
-		return nil, fmt.Errorf("deployment/%s failed to rollout: %v", olmOperatorKey.Name, err)
+		return phaseError(ctx, PhaseRollout, fmt.Errorf("deployment/%s failed to rollout: %v", olmOperatorKey.Name, err))
 	}
 
-	log.Print("Waiting for deployment/catalog-operator rollout to complete")
 	catalogOperatorKey := types.NamespacedName{Namespace: namespace, Name: catalogOperatorName}
+	log.Info(ctx, "Waiting for rollout to complete", olmresourceclient.KeyFields("Deployment", catalogOperatorKey),
+		phaseField("rollout"))
 	if err := c.DoRolloutWait(ctx, catalogOperatorKey); err != nil {
-		return nil, fmt.Errorf("deployment/%s failed to rollout: %v", catalogOperatorKey.Name, err)
+		return phaseError(ctx, PhaseRollout, fmt.Errorf("deployment/%s failed to rollout: %v", catalogOperatorKey.Name, err))
 	}
 
 	subscriptions := filterResources(resources, func(r unstructured.Unstructured) bool {
@@ -150,50 +259,42 @@ func (c Client) InstallVersion(ctx context.Context, namespace, version string) (
 
 	for _, sub := range subscriptions {
 		subscriptionKey := types.NamespacedName{Namespace: sub.GetNamespace(), Name: sub.GetName()}
-		log.Printf("Waiting for subscription/%s to install CSV", subscriptionKey.Name)
+		log.Info(ctx, "Waiting for subscription to install CSV",
+			olmresourceclient.KeyFields(olmapiv1alpha1.SubscriptionKind, subscriptionKey), phaseField("subscription"))
 		csvKey, err := c.getSubscriptionCSV(ctx, subscriptionKey)
 		if err != nil {
-			return nil, fmt.Errorf("subscription/%s failed to install CSV: %v", subscriptionKey.Name, err)
+			return phaseError(ctx, PhaseSubscription,
+				fmt.Errorf("subscription/%s failed to install CSV: %v", subscriptionKey.Name, err))
 		}
-		log.Printf("Waiting for clusterserviceversion/%s to reach 'Succeeded' phase", csvKey.Name)
+		log.Info(ctx, "Waiting for ClusterServiceVersion to reach 'Succeeded' phase",
+			olmresourceclient.KeyFields(olmapiv1alpha1.ClusterServiceVersionKind, csvKey), phaseField("csv"))
 		if err := c.DoCSVWait(ctx, csvKey); err != nil {
-			return nil, fmt.Errorf("clusterserviceversion/%s failed to reach 'Succeeded' phase",
-				csvKey.Name)
+			return phaseError(ctx, PhaseCSV, fmt.Errorf("clusterserviceversion/%s failed to reach 'Succeeded' phase",
+				csvKey.Name))
 		}
 	}
 
 	packageServerKey := types.NamespacedName{Namespace: namespace, Name: packageServerName}
-	log.Printf("Waiting for deployment/%s rollout to complete", packageServerKey.Name)
+	// The packageserver deployment is owned by its CSV, so on upgrades the
+	// old deployment is still around until OLM has reconciled the new CSV.
+	log.Info(ctx, "Waiting for ClusterServiceVersion to reach 'Succeeded' phase",
+		olmresourceclient.KeyFields(olmapiv1alpha1.ClusterServiceVersionKind, packageServerKey), phaseField("csv"))
+	if err := c.DoCSVWait(ctx, packageServerKey); err != nil {
+		return phaseError(ctx, PhaseCSV,
+			fmt.Errorf("clusterserviceversion/%s failed to reach 'Succeeded' phase: %v", packageServerKey.Name, err))
+	}
+	log.Info(ctx, "Waiting for rollout to complete", olmresourceclient.KeyFields("Deployment", packageServerKey),
+		phaseField("rollout"))
 	if err := c.DoRolloutWait(ctx, packageServerKey); err != nil {
-		return nil, fmt.Errorf("deployment/%s failed to rollout: %v", packageServerKey.Name, err)
-	}
-
-	objs = toObjects(append(crds, resources...)...)
-	status = c.GetObjectsStatus(ctx, objs...)
-	return &status, nil
-}
-
-func (c Client) UninstallVersion(ctx context.Context, version string) error {
-	crds, resources, err := c.getResources(ctx, version)
-	if err != nil {
-		return fmt.Errorf("failed to get resources: %v", err)
+		return phaseError(ctx, PhaseRollout, fmt.Errorf("deployment/%s failed to rollout: %v", packageServerKey.Name, err))
 	}
-	objs := toObjects(append(crds, resources...)...)
-
-	status := c.GetObjectsStatus(ctx, objs...)
-	installed, err := status.HasInstalledResources()
-	if !installed && err == nil {
-		return olmresourceclient.ErrOLMNotInstalled
-	}
-
-	log.Infof("Uninstalling resources for version %q", version)
-	return c.DoDelete(ctx, objs...)
+	return nil
 }
 
-func (c Client) GetStatus(ctx context.Context, version string) (*olmresourceclient.Status, error) {
-	crds, resources, err := c.getResources(ctx, version)
+func (c Client) GetStatus(ctx context.Context, opts InstallOptions) (*olmresourceclient.Status, error) {
+	crds, resources, err := c.getResources(ctx, opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get resources: %v", err)
+		return nil, fmt.Errorf("failed to get resources: %w", err)
 	}
 	objs := toObjects(append(crds, resources...)...)
 
@@ -207,61 +308,19 @@ func (c Client) GetStatus(ctx context.Context, version string) (*olmresourceclie
 	return &status, nil
 }
 
-func (c Client) getResources(ctx context.Context, version string) ([]unstructured.Unstructured, []unstructured.Unstructured, error) {
-	log.Infof("Fetching CRDs for version %q", version)
-
-	resolvedVersion := formatVersion(version)
-
-	var crdResources, olmResources []unstructured.Unstructured
-	var err error
-
-	// If the manifests for the requested version are saved as bindata in SDK, use
-	// them instead of fetching them from github.
-	if olmmanifests.HasVersion(version) {
-		log.Infof("Using locally stored resource manifests")
-		crdManifestBindataPath := filepath.Join(bindataManifestPath, version+"-crds.yaml")
-		crdResources, err = getPackagedManifests(crdManifestBindataPath)
-		if err != nil {
-			return nil, nil, err
-		}
-
-		olmManifestBindataPath := filepath.Join(bindataManifestPath, version+"-olm.yaml")
-		olmResources, err = getPackagedManifests(olmManifestBindataPath)
-		if err != nil {
-			return nil, nil, err
-		}
-	} else {
-		log.Infof("Fetching resources for resolved version %q", resolvedVersion)
-		crdResources, err = c.getCRDs(ctx, resolvedVersion)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to fetch CRDs: %v", err)
-		}
-
-		olmResources, err = c.getOLM(ctx, resolvedVersion)
-		if err != nil {
-			return nil, nil, fmt.Errorf("failed to fetch resources: %v", err)
-		}
-	}
-
-	return crdResources, olmResources, nil
-}
-
-func (c Client) getCRDs(ctx context.Context, version string) ([]unstructured.Unstructured, error) {
-	resp, err := c.doRequest(ctx, c.crdsURL(version))
+// getResources returns the CRDs and the other resources of the OLM release
+// in opts, customized as requested by opts. Both are labelled as managed.
+func (c Client) getResources(ctx context.Context, opts InstallOptions) ([]unstructured.Unstructured, []unstructured.Unstructured, error) {
+	crdResources, olmResources, err := c.fetchManifests(ctx, opts)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %v", err)
+		return nil, nil, err
 	}
-	defer resp.Body.Close()
-	return decodeResources(resp.Body)
-}
-
-func (c Client) getOLM(ctx context.Context, version string) ([]unstructured.Unstructured, error) {
-	resp, err := c.doRequest(ctx, c.olmURL(version))
+	labelManaged(crdResources)
+	olmResources, err = opts.customize(olmResources)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %v", err)
+		return nil, nil, err
 	}
-	defer resp.Body.Close()
-	return decodeResources(resp.Body)
+	return crdResources, olmResources, nil
 }
 
 func getPackagedManifests(manifestPath string) ([]unstructured.Unstructured, error) {
@@ -292,12 +351,9 @@ func formatVersion(version string) string {
 	return "v" + sv.String()
 }
 
-func (c Client) crdsURL(version string) string {
-	return fmt.Sprintf("%s/crds.yaml", c.getBaseDownloadURL(version))
-}
-
-func (c Client) olmURL(version string) string {
-	return fmt.Sprintf("%s/olm.yaml", c.getBaseDownloadURL(version))
+// manifestURL returns the download URL of the manifest file of the OLM release version.
+func (c Client) manifestURL(version, file string) string {
+	return fmt.Sprintf("%s/%s", c.getBaseDownloadURL(version), file)
 }
 
 func (c Client) getBaseDownloadURL(version string) string {
@@ -307,32 +363,94 @@ func (c Client) getBaseDownloadURL(version string) string {
 	return fmt.Sprintf("%s/download/%s", c.BaseDownloadURL, version)
 }
 
+// downloadRetryBackoff is the delay before the first download retry, it
+// doubles with every further attempt.
+var downloadRetryBackoff = time.Second
+
+// doRequest GETs url, retrying with an exponential backoff on connection
+// errors and on responses that indicate a temporary failure.
 func (c Client) doRequest(ctx context.Context, url string) (*http.Response, error) {
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	backoff := downloadRetryBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := c.doRequestOnce(ctx, http.MethodGet, url)
+		if err == nil || attempt >= c.Retries || !isRetryable(resp) || ctx.Err() != nil {
+			return resp, err
+		}
+		c.Logger().Warn(ctx, "Manifest download failed, retrying", map[string]interface{}{
+			"url":     url,
+			"attempt": attempt + 1,
+			"error":   err.Error(),
+		})
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// download returns the content at url.
+func (c Client) download(ctx context.Context, url string) ([]byte, error) {
+	resp, err := c.doRequest(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// githubHosts serve the GitHub releases of OLM and accept Client.GitHubToken.
+var githubHosts = map[string]bool{
+	"github.com":                    true,
+	"api.github.com":                true,
+	"objects.githubusercontent.com": true,
+}
+
+// isRetryable reports whether a failed request should be retried. A nil
+// response means the request failed before a status code was received.
+func isRetryable(resp *http.Response) bool {
+	return resp == nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// doRequestOnce sends a method request, GET or HEAD, to url. On failure, the
+// returned response only carries the status code, its body is already closed.
+func (c Client) doRequestOnce(ctx context.Context, method, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %v", err)
+		return &http.Response{}, fmt.Errorf("create request: %v", err)
+	}
+	for name, value := range c.Headers {
+		req.Header.Set(name, value)
+	}
+	if c.GitHubToken != "" && githubHosts[req.URL.Hostname()] {
+		req.Header.Set("Authorization", "Bearer "+c.GitHubToken)
 	}
-	req = req.WithContext(ctx)
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("failed GET '%s': %v", url, err)
+		return nil, fmt.Errorf("failed %s '%s': %v", method, url, err)
 	}
 	if resp.StatusCode != http.StatusOK {
 		defer resp.Body.Close()
 		body, err := io.ReadAll(resp.Body)
-		msg := fmt.Sprintf("failed GET '%s': unexpected status code %d, expected %d", url, resp.StatusCode, http.StatusOK)
+		msg := fmt.Sprintf("failed %s '%s': unexpected status code %d, expected %d", method, url, resp.StatusCode, http.StatusOK)
 		if resp.StatusCode == 404 {
-			return nil, fmt.Errorf("%s; manifests may not exist for this OLM release,"+
-				"please check https://github.com/operator-framework/operator-lifecycle-manager/releases for olm.yaml and crds.yaml", msg)
+			return resp, fmt.Errorf("%s; manifests may not exist for this OLM release, "+
+				"please check %s for olm.yaml and crds.yaml", msg, c.BaseDownloadURL)
 		}
 		if err != nil {
-			return nil, fmt.Errorf("%s: %v", msg, err)
+			return resp, fmt.Errorf("%s: %v", msg, err)
 		}
-		return nil, fmt.Errorf("%s: %s", msg, string(body))
+		return resp, fmt.Errorf("%s: %s", msg, string(body))
 	}
 	return resp, nil
 }
 
+// phaseField returns the log field naming the install phase.
+func phaseField(phase string) map[string]interface{} {
+	return map[string]interface{}{"phase": phase}
+}
+
 func toObjects(us ...unstructured.Unstructured) (objs []client.Object) {
 	for i := range us {
 		objs = append(objs, &us[i])
@@ -383,7 +501,9 @@ func (c Client) getSubscriptionCSV(ctx context.Context, subKey types.NamespacedN
 			Namespace: subKey.Namespace,
 			Name:      installedCSV,
 		}
-		log.Printf("  Found installed CSV %q", installedCSV)
+		c.Logger().Debug(pctx, "Found installed CSV",
+			olmresourceclient.KeyFields(olmapiv1alpha1.SubscriptionKind, subKey),
+			map[string]interface{}{"csv": installedCSV})
 		return true, nil
 	}
 	return csvKey, wait.PollUntilContextCancel(ctx, time.Second, false, subscriptionInstalledCSV)
@@ -0,0 +1,146 @@
+package installer
+
+import (
+	"fmt"
+
+	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeploymentOptions tunes the deployment of an OLM component. The zero value
+// keeps the upstream settings.
+type DeploymentOptions struct {
+	// Replicas replaces the number of replicas.
+	Replicas *int32
+	// Resources replaces the compute resources of the container.
+	Resources *corev1.ResourceRequirements
+	// NodeSelector is merged into the upstream node selector.
+	NodeSelector map[string]string
+	// Tolerations replaces the tolerations of the pods.
+	Tolerations []corev1.Toleration
+	// Affinity replaces the affinity of the pods.
+	Affinity *corev1.Affinity
+	// PriorityClassName sets the priority class of the pods.
+	PriorityClassName string
+	// ExtraArgs are appended to the arguments of the container.
+	ExtraArgs []string
+}
+
+// apply tunes the deployment spec, a DeploymentSpec in unstructured form.
+func (o DeploymentOptions) apply(spec map[string]interface{}) error {
+	if o.Replicas != nil {
+		spec["replicas"] = int64(*o.Replicas)
+	}
+
+	podSpec := []string{"template", "spec"}
+	if len(o.NodeSelector) > 0 {
+		selector, _, err := unstructured.NestedStringMap(spec, append(podSpec, "nodeSelector")...)
+		if err != nil {
+			return err
+		}
+		if selector == nil {
+			selector = map[string]string{}
+		}
+		for key, value := range o.NodeSelector {
+			selector[key] = value
+		}
+		if err := unstructured.SetNestedStringMap(spec, selector, append(podSpec, "nodeSelector")...); err != nil {
+			return err
+		}
+	}
+	if o.Tolerations != nil {
+		tolerations := make([]interface{}, 0, len(o.Tolerations))
+		for i := range o.Tolerations {
+			toleration, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&o.Tolerations[i])
+			if err != nil {
+				return err
+			}
+			tolerations = append(tolerations, toleration)
+		}
+		if err := unstructured.SetNestedSlice(spec, tolerations, append(podSpec, "tolerations")...); err != nil {
+			return err
+		}
+	}
+	if o.Affinity != nil {
+		affinity, err := runtime.DefaultUnstructuredConverter.ToUnstructured(o.Affinity)
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedMap(spec, affinity, append(podSpec, "affinity")...); err != nil {
+			return err
+		}
+	}
+	if o.PriorityClassName != "" {
+		if err := unstructured.SetNestedField(spec, o.PriorityClassName, append(podSpec, "priorityClassName")...); err != nil {
+			return err
+		}
+	}
+
+	if o.Resources == nil && len(o.ExtraArgs) == 0 {
+		return nil
+	}
+	var resources map[string]interface{}
+	if o.Resources != nil {
+		var err error
+		if resources, err = runtime.DefaultUnstructuredConverter.ToUnstructured(o.Resources); err != nil {
+			return err
+		}
+	}
+	return updateNestedMaps(spec, func(container map[string]interface{}) {
+		if resources != nil {
+			container["resources"] = runtime.DeepCopyJSON(resources)
+		}
+		if len(o.ExtraArgs) > 0 {
+			args, _ := container["args"].([]interface{})
+			for _, arg := range o.ExtraArgs {
+				args = append(args, arg)
+			}
+			container["args"] = args
+		}
+	}, append(podSpec, "containers")...)
+}
+
+// tuneDeployments applies the deployment options of opts to the OLM
+// deployments in resources, packageserver is deployed by its CSV.
+func tuneDeployments(resources []unstructured.Unstructured, opts InstallOptions) error {
+	tune := func(spec interface{}, options DeploymentOptions) error {
+		if m, ok := spec.(map[string]interface{}); ok {
+			return options.apply(m)
+		}
+		return nil
+	}
+	for i := range resources {
+		r := &resources[i]
+		var err error
+		switch {
+		case r.GetKind() == "Deployment" && r.GetName() == olmOperatorName:
+			err = tune(r.Object["spec"], opts.OLMOperator)
+		case r.GetKind() == "Deployment" && r.GetName() == catalogOperatorName:
+			err = tune(r.Object["spec"], opts.CatalogOperator)
+		case r.GetKind() == olmapiv1alpha1.ClusterServiceVersionKind && r.GetName() == packageServerName:
+			err = updateNestedMapsErr(r.Object, func(deployment map[string]interface{}) error {
+				return tune(deployment["spec"], opts.PackageServer)
+			}, "spec", "install", "spec", "deployments")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to tune %s %q: %v", r.GetKind(), r.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// updateNestedMapsErr is updateNestedMaps with an update that can fail.
+func updateNestedMapsErr(obj map[string]interface{}, update func(map[string]interface{}) error, fields ...string) error {
+	var updateErr error
+	err := updateNestedMaps(obj, func(m map[string]interface{}) {
+		if updateErr == nil {
+			updateErr = update(m)
+		}
+	}, fields...)
+	if updateErr != nil {
+		return updateErr
+	}
+	return err
+}
@@ -0,0 +1,103 @@
+package installer
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+)
+
+var _ = Describe("tuneDeployments", func() {
+	var resources []unstructured.Unstructured
+
+	BeforeEach(func() {
+		var err error
+		resources, err = getPackagedManifests(filepath.Join(bindataManifestPath, "0.26.0-olm.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	find := func(kind, name string) unstructured.Unstructured {
+		for _, r := range resources {
+			if r.GetKind() == kind && r.GetName() == name {
+				return r
+			}
+		}
+		Fail("resource not found: " + kind + "/" + name)
+		return unstructured.Unstructured{}
+	}
+	packageServerSpec := func() map[string]interface{} {
+		csv := find("ClusterServiceVersion", packageServerName)
+		deployments, _, err := unstructured.NestedSlice(csv.Object, "spec", "install", "spec", "deployments")
+		Expect(err).NotTo(HaveOccurred())
+		return deployments[0].(map[string]interface{})["spec"].(map[string]interface{})
+	}
+
+	It("keeps the upstream deployments without options", func() {
+		olmOperator := find("Deployment", olmOperatorName)
+		upstream := olmOperator.DeepCopy()
+		Expect(tuneDeployments(resources, InstallOptions{})).To(Succeed())
+		Expect(find("Deployment", olmOperatorName).Object).To(Equal(upstream.Object))
+	})
+
+	It("schedules the deployments on dedicated nodes", func() {
+		Expect(tuneDeployments(resources, InstallOptions{
+			CatalogOperator: DeploymentOptions{
+				NodeSelector: map[string]string{"node-role.kubernetes.io/infra": ""},
+				Tolerations: []corev1.Toleration{{
+					Key: "node-role.kubernetes.io/infra", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule,
+				}},
+				PriorityClassName: "system-cluster-critical",
+			},
+		})).To(Succeed())
+
+		catalogOperator := find("Deployment", catalogOperatorName)
+		selector, _, err := unstructured.NestedStringMap(catalogOperator.Object, "spec", "template", "spec", "nodeSelector")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selector).To(Equal(map[string]string{"kubernetes.io/os": "linux", "node-role.kubernetes.io/infra": ""}))
+		tolerations, _, err := unstructured.NestedSlice(catalogOperator.Object, "spec", "template", "spec", "tolerations")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tolerations).To(Equal([]interface{}{map[string]interface{}{
+			"key": "node-role.kubernetes.io/infra", "operator": "Exists", "effect": "NoSchedule",
+		}}))
+		priorityClass, _, _ := unstructured.NestedString(catalogOperator.Object, "spec", "template", "spec", "priorityClassName")
+		Expect(priorityClass).To(Equal("system-cluster-critical"))
+
+		olmOperator := find("Deployment", olmOperatorName)
+		_, found, _ := unstructured.NestedSlice(olmOperator.Object, "spec", "template", "spec", "tolerations")
+		Expect(found).To(BeFalse())
+	})
+
+	It("tunes the packageserver deployment of its CSV", func() {
+		Expect(tuneDeployments(resources, InstallOptions{
+			PackageServer: DeploymentOptions{
+				Replicas: ptr.To[int32](3),
+				Resources: &corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+				},
+				Affinity: &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+					PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{
+						Weight:          100,
+						PodAffinityTerm: corev1.PodAffinityTerm{TopologyKey: "kubernetes.io/hostname"},
+					}},
+				}},
+				ExtraArgs: []string{"--v=2"},
+			},
+		})).To(Succeed())
+
+		spec := packageServerSpec()
+		Expect(spec["replicas"]).To(Equal(int64(3)))
+		terms, _, err := unstructured.NestedSlice(spec, "template", "spec", "affinity", "podAntiAffinity",
+			"preferredDuringSchedulingIgnoredDuringExecution")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(terms).To(HaveLen(1))
+		containers, _, err := unstructured.NestedSlice(spec, "template", "spec", "containers")
+		Expect(err).NotTo(HaveOccurred())
+		container := containers[0].(map[string]interface{})
+		Expect(container["resources"]).To(Equal(map[string]interface{}{"limits": map[string]interface{}{"memory": "256Mi"}}))
+		Expect(container["args"]).To(Equal([]interface{}{"--v=2"}))
+	})
+})
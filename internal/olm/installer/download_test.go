@@ -0,0 +1,105 @@
+package installer
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+var _ = Describe("doRequest", func() {
+	var (
+		c        Client
+		server   *httptest.Server
+		requests int
+		statuses []int
+	)
+
+	BeforeEach(func() {
+		downloadRetryBackoff = time.Millisecond
+		requests = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			status := statuses[requests]
+			requests++
+			if r.Header.Get("Authorization") != "Bearer token" {
+				status = http.StatusUnauthorized
+			}
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte("body"))
+		}))
+		c = Client{Client: &olmresourceclient.Client{}}
+		Expect(c.SetDownloadOptions(DownloadOptions{
+			BaseURL: server.URL,
+			Headers: map[string]string{"Authorization": "Bearer token"},
+			Retries: 2,
+		})).To(Succeed())
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("retries temporary failures", func() {
+		statuses = []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK}
+		resp, err := c.doRequest(context.TODO(), server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(body)).To(Equal("body"))
+		Expect(requests).To(Equal(3))
+	})
+
+	It("gives up after the configured retries", func() {
+		statuses = []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK}
+		_, err := c.doRequest(context.TODO(), server.URL)
+		Expect(err).To(MatchError(ContainSubstring("unexpected status code 502")))
+		Expect(requests).To(Equal(3))
+	})
+
+	It("does not retry missing manifests", func() {
+		statuses = []int{http.StatusNotFound, http.StatusOK}
+		_, err := c.doRequest(context.TODO(), server.URL)
+		Expect(err).To(MatchError(ContainSubstring("manifests may not exist for this OLM release")))
+		Expect(requests).To(Equal(1))
+	})
+})
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+var _ = Describe("GitHubToken", func() {
+	It("is only sent to the GitHub hosts", func() {
+		authorization := map[string]string{}
+		c := Client{
+			Client: &olmresourceclient.Client{},
+			HTTPClient: http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+				authorization[r.URL.Hostname()] = r.Header.Get("Authorization")
+				return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(""))}, nil
+			})},
+			GitHubToken: "token",
+		}
+		for _, url := range []string{
+			"https://api.github.com/repos/operator-framework/operator-lifecycle-manager/releases",
+			"https://objects.githubusercontent.com/asset",
+			"https://mirror.example.com/download/v0.27.0/crds.yaml",
+		} {
+			resp, err := c.doRequestOnce(context.TODO(), http.MethodGet, url)
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+		}
+		Expect(authorization).To(Equal(map[string]string{
+			"api.github.com":                "Bearer token",
+			"objects.githubusercontent.com": "Bearer token",
+			"mirror.example.com":            "",
+		}))
+	})
+})
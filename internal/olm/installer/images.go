@@ -0,0 +1,144 @@
+package installer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ImageOverride replaces the image of an OLM component.
+type ImageOverride struct {
+	// Image replaces the whole image reference, e.g.
+	// "registry.example.com/operator-framework/olm:v0.26.0".
+	Image string
+	// Digest pins the image to a digest such as "sha256:...", replacing its
+	// tag or digest.
+	Digest string
+}
+
+// ImageOverrides replaces the images of the OLM components, e.g. with the
+// images of a registry mirror in air-gapped clusters. The zero value keeps
+// the upstream images.
+type ImageOverrides struct {
+	// Registry replaces the registry of every upstream OLM image, e.g.
+	// "mirror.example.com/quay.io" turns "quay.io/operator-framework/olm"
+	// into "mirror.example.com/quay.io/operator-framework/olm". Components
+	// with an explicit Image are not rewritten.
+	Registry string
+	// OLMOperator is the image of the olm-operator deployment.
+	OLMOperator ImageOverride
+	// CatalogOperator is the image of the catalog-operator deployment.
+	CatalogOperator ImageOverride
+	// PackageServer is the image of the packageserver deployment.
+	PackageServer ImageOverride
+	// Util is the image catalog-operator unpacks bundles with, its
+	// --util-image flag.
+	Util ImageOverride
+	// ConfigmapServer is the image serving ConfigMap based catalogs,
+	// the --configmapServerImage flag of catalog-operator.
+	ConfigmapServer ImageOverride
+}
+
+// digestPattern matches OCI content digests such as "sha256:<hex>".
+var digestPattern = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-zA-Z0-9=_-]{32,}$`)
+
+// validate checks the digests of o.
+func (o ImageOverrides) validate() error {
+	for _, component := range []struct {
+		name     string
+		override ImageOverride
+	}{
+		{"olm-operator", o.OLMOperator},
+		{"catalog-operator", o.CatalogOperator},
+		{"packageserver", o.PackageServer},
+		{"util", o.Util},
+		{"configmap-server", o.ConfigmapServer},
+	} {
+		if digest := component.override.Digest; digest != "" && !digestPattern.MatchString(digest) {
+			return fmt.Errorf("invalid digest %q of the %s image, expected e.g. \"sha256:<hex>\"",
+				digest, component.name)
+		}
+	}
+	return nil
+}
+
+// resolve returns the image that replaces the upstream image ref.
+func (o ImageOverride) resolve(ref, registry string) string {
+	if o.Image != "" {
+		ref = o.Image
+	} else if registry != "" {
+		ref = rewriteRegistry(ref, registry)
+	}
+	if o.Digest != "" {
+		ref = trimTagAndDigest(ref) + "@" + o.Digest
+	}
+	return ref
+}
+
+// rewriteRegistry replaces the registry of the image ref with registry.
+// Images without an explicit registry are assumed to come from Docker Hub
+// and are prefixed with registry.
+func rewriteRegistry(ref, registry string) string {
+	registry = strings.TrimSuffix(registry, "/")
+	if domain, remainder, ok := strings.Cut(ref, "/"); ok &&
+		(strings.ContainsAny(domain, ".:") || domain == "localhost") {
+		return registry + "/" + remainder
+	}
+	return registry + "/" + ref
+}
+
+// trimTagAndDigest returns the repository of the image ref.
+func trimTagAndDigest(ref string) string {
+	ref, _, _ = strings.Cut(ref, "@")
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	return ref
+}
+
+// overrideImages replaces the images of the OLM components in resources.
+func overrideImages(resources []unstructured.Unstructured, images ImageOverrides) error {
+	if images == (ImageOverrides{}) {
+		return nil
+	}
+	if err := images.validate(); err != nil {
+		return err
+	}
+
+	setImage := func(override ImageOverride) func(map[string]interface{}) {
+		return func(container map[string]interface{}) {
+			if ref, ok := container["image"].(string); ok {
+				container["image"] = override.resolve(ref, images.Registry)
+			}
+		}
+	}
+	for i := range resources {
+		r := &resources[i]
+		var err error
+		switch {
+		case r.GetKind() == "Deployment" && r.GetName() == olmOperatorName:
+			err = updateNestedMaps(r.Object, setImage(images.OLMOperator), "spec", "template", "spec", "containers")
+		case r.GetKind() == "Deployment" && r.GetName() == catalogOperatorName:
+			err = updateNestedMaps(r.Object, func(container map[string]interface{}) {
+				setImage(images.CatalogOperator)(container)
+				rewriteFlags(container, map[string]struct{}{"util-image": {}}, func(ref string) string {
+					return images.Util.resolve(ref, images.Registry)
+				})
+				rewriteFlags(container, map[string]struct{}{"configmapServerImage": {}}, func(ref string) string {
+					return images.ConfigmapServer.resolve(ref, images.Registry)
+				})
+			}, "spec", "template", "spec", "containers")
+		case r.GetKind() == olmapiv1alpha1.ClusterServiceVersionKind && r.GetName() == packageServerName:
+			err = updateNestedMaps(r.Object, func(deployment map[string]interface{}) {
+				_ = updateNestedMaps(deployment, setImage(images.PackageServer), "spec", "template", "spec", "containers")
+			}, "spec", "install", "spec", "deployments")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to override the images of %s %q: %v", r.GetKind(), r.GetName(), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package installer
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	upstreamOLMImage = "quay.io/operator-framework/olm@sha256:30481639e89a0cb282fc1855c1bfdde96ec5ee36d1c651c2d0d79c8d249e3ed5"
+	testDigest       = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+)
+
+var _ = Describe("ImageOverride", func() {
+	DescribeTable("resolve",
+		func(override ImageOverride, registry, ref, expected string) {
+			Expect(override.resolve(ref, registry)).To(Equal(expected))
+		},
+		Entry("keeps the upstream image", ImageOverride{}, "", upstreamOLMImage, upstreamOLMImage),
+		Entry("rewrites the registry", ImageOverride{}, "mirror.example.com/quay.io/", upstreamOLMImage,
+			"mirror.example.com/quay.io/operator-framework/olm@sha256:30481639e89a0cb282fc1855c1bfdde96ec5ee36d1c651c2d0d79c8d249e3ed5"),
+		Entry("prefixes Docker Hub images", ImageOverride{}, "mirror.example.com", "library/busybox:1.36",
+			"mirror.example.com/library/busybox:1.36"),
+		Entry("replaces the image regardless of the registry", ImageOverride{Image: "localhost:5000/olm:v0.26.0"},
+			"mirror.example.com", upstreamOLMImage, "localhost:5000/olm:v0.26.0"),
+		Entry("pins a tagged image to a digest", ImageOverride{Image: "localhost:5000/olm:v0.26.0", Digest: testDigest},
+			"", upstreamOLMImage, "localhost:5000/olm@"+testDigest),
+		Entry("replaces the digest of the upstream image", ImageOverride{Digest: testDigest},
+			"mirror.example.com", upstreamOLMImage, "mirror.example.com/operator-framework/olm@"+testDigest),
+	)
+})
+
+var _ = Describe("overrideImages", func() {
+	var resources []unstructured.Unstructured
+
+	BeforeEach(func() {
+		var err error
+		resources, err = getPackagedManifests(filepath.Join(bindataManifestPath, "0.26.0-olm.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	find := func(kind, name string) unstructured.Unstructured {
+		for _, r := range resources {
+			if r.GetKind() == kind && r.GetName() == name {
+				return r
+			}
+		}
+		Fail("resource not found: " + kind + "/" + name)
+		return unstructured.Unstructured{}
+	}
+	container := func(obj map[string]interface{}, fields ...string) map[string]interface{} {
+		containers, _, err := unstructured.NestedSlice(obj, fields...)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(containers).NotTo(BeEmpty())
+		return containers[0].(map[string]interface{})
+	}
+
+	It("mirrors every OLM image", func() {
+		Expect(overrideImages(resources, ImageOverrides{Registry: "mirror.example.com"})).To(Succeed())
+
+		mirrored := "mirror.example.com/operator-framework/olm@sha256:30481639e89a0cb282fc1855c1bfdde96ec5ee36d1c651c2d0d79c8d249e3ed5"
+		olmOperator := find("Deployment", olmOperatorName)
+		Expect(container(olmOperator.Object, "spec", "template", "spec", "containers")["image"]).To(Equal(mirrored))
+
+		catalogOperator := container(find("Deployment", catalogOperatorName).Object, "spec", "template", "spec", "containers")
+		Expect(catalogOperator["image"]).To(Equal(mirrored))
+		Expect(catalogOperator["args"]).To(ContainElements(
+			"--configmapServerImage=mirror.example.com/operator-framework/configmap-operator-registry:latest",
+			mirrored,
+		))
+
+		csv := find("ClusterServiceVersion", packageServerName)
+		deployments, _, err := unstructured.NestedSlice(csv.Object, "spec", "install", "spec", "deployments")
+		Expect(err).NotTo(HaveOccurred())
+		packageServer := container(deployments[0].(map[string]interface{}), "spec", "template", "spec", "containers")
+		Expect(packageServer["image"]).To(Equal(mirrored))
+
+		catalog := find("CatalogSource", "operatorhubio-catalog")
+		image, _, _ := unstructured.NestedString(catalog.Object, "spec", "image")
+		Expect(image).To(Equal("quay.io/operatorhubio/catalog:latest"))
+	})
+
+	It("overrides the components separately", func() {
+		Expect(overrideImages(resources, ImageOverrides{
+			Util:            ImageOverride{Image: "registry.example.com/olm-util:v1"},
+			ConfigmapServer: ImageOverride{Digest: testDigest},
+		})).To(Succeed())
+
+		catalogOperator := container(find("Deployment", catalogOperatorName).Object, "spec", "template", "spec", "containers")
+		Expect(catalogOperator["image"]).To(Equal(upstreamOLMImage))
+		Expect(catalogOperator["args"]).To(ContainElements(
+			"--configmapServerImage=quay.io/operator-framework/configmap-operator-registry@"+testDigest,
+			"registry.example.com/olm-util:v1",
+		))
+	})
+
+	It("rejects an invalid digest", func() {
+		err := overrideImages(resources, ImageOverrides{PackageServer: ImageOverride{Digest: "latest"}})
+		Expect(err).To(MatchError(ContainSubstring("invalid digest \"latest\" of the packageserver image")))
+	})
+})
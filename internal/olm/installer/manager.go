@@ -20,7 +20,6 @@ import (
 	"sync"
 	"time"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 )
@@ -30,14 +29,17 @@ const (
 	DefaultTimeout = time.Minute * 2
 	// DefaultOLMNamespace is the namespace where OLM is installed.
 	DefaultOLMNamespace = "olm"
+	// DefaultOperatorsNamespace is the namespace of the global OperatorGroup.
+	DefaultOperatorsNamespace = "operators"
 )
 
 type Manager struct {
-	Client       *Client
-	Version      string
-	Timeout      time.Duration
-	OLMNamespace string
-	once         sync.Once
+	Client             *Client
+	Version            string
+	Timeout            time.Duration
+	OLMNamespace       string
+	OperatorsNamespace string
+	once               sync.Once
 }
 
 func (m *Manager) initialize() (err error) {
@@ -62,6 +64,9 @@ func (m *Manager) initialize() (err error) {
 		if m.OLMNamespace == "" {
 			m.OLMNamespace = DefaultOLMNamespace
 		}
+		if m.OperatorsNamespace == "" {
+			m.OperatorsNamespace = DefaultOperatorsNamespace
+		}
 	})
 	return err
 }
@@ -74,12 +79,12 @@ func (m *Manager) Install() error {
 	ctx, cancel := context.WithTimeout(context.Background(), m.Timeout)
 	defer cancel()
 
-	status, err := m.Client.InstallVersion(ctx, m.OLMNamespace, m.Version)
+	status, err := m.Client.InstallVersion(ctx, m.installOptions())
 	if err != nil {
 		return err
 	}
 
-	log.Infof("Successfully installed OLM version %q", m.Version)
+	m.Client.Logger().Info(ctx, "Successfully installed OLM", map[string]interface{}{"version": m.Version})
 	fmt.Print("\n")
 	fmt.Println(status)
 	return nil
@@ -98,18 +103,18 @@ func (m *Manager) Uninstall() error {
 			return fmt.Errorf("error getting installed OLM version (set --version to override the default version): %v", err)
 		}
 	} else if m.Version != "" {
-		if version != m.Version {
+		if !SameVersion(version, m.Version) {
 			return fmt.Errorf("mismatched installed version %q vs. supplied version %q", version, m.Version)
 		}
 	} else {
 		m.Version = version
 	}
 
-	if err := m.Client.UninstallVersion(ctx, m.Version); err != nil {
+	if err := m.Client.UninstallVersion(ctx, m.installOptions()); err != nil {
 		return err
 	}
 
-	log.Infof("Successfully uninstalled OLM version %q", m.Version)
+	m.Client.Logger().Info(ctx, "Successfully uninstalled OLM", map[string]interface{}{"version": m.Version})
 	return nil
 }
 
@@ -126,24 +131,32 @@ func (m *Manager) Status() error {
 			return fmt.Errorf("error getting installed OLM version (set --version to override the default version): %v", err)
 		}
 	} else if m.Version != "" {
-		if version != m.Version {
+		if !SameVersion(version, m.Version) {
 			return fmt.Errorf("mismatched installed version %q vs. supplied version %q", version, m.Version)
 		}
 	} else {
 		m.Version = version
 	}
 
-	status, err := m.Client.GetStatus(ctx, m.Version)
+	status, err := m.Client.GetStatus(ctx, m.installOptions())
 	if err != nil {
 		return err
 	}
 
-	log.Infof("Successfully got OLM status for version %q", m.Version)
+	m.Client.Logger().Info(ctx, "Successfully got OLM status", map[string]interface{}{"version": m.Version})
 	fmt.Print("\n")
 	fmt.Println(status)
 	return nil
 }
 
+func (m *Manager) installOptions() InstallOptions {
+	return InstallOptions{
+		Version:            m.Version,
+		Namespace:          m.OLMNamespace,
+		OperatorsNamespace: m.OperatorsNamespace,
+	}
+}
+
 func (m *Manager) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.DurationVar(&m.Timeout, "timeout", DefaultTimeout, "time to wait for the command to complete before failing")
 }
@@ -0,0 +1,170 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	olmmanifests "github.com/kaplan-michael/terraform-provider-olm/internal/bindata/olm"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// The manifest files of an OLM release.
+const (
+	crdsManifest = "crds.yaml"
+	olmManifest  = "olm.yaml"
+)
+
+// ManifestSources replaces the upstream manifests of the OLM release, e.g.
+// with a patched or release candidate build. Each source is either an
+// http(s) URL, inline YAML or JSON, or the path of a local file. Empty
+// sources keep the upstream manifests of the version.
+type ManifestSources struct {
+	// CRDs replaces crds.yaml.
+	CRDs string
+	// OLM replaces olm.yaml.
+	OLM string
+}
+
+// ManifestChecksums are the expected hex encoded sha256 checksums of the
+// manifests. They take precedence over the checksums embedded for vetted
+// releases and over those trusted on first use, see
+// InstallOptions.TrustOnFirstUse.
+type ManifestChecksums struct {
+	// CRDs is the checksum of crds.yaml.
+	CRDs string
+	// OLM is the checksum of olm.yaml.
+	OLM string
+}
+
+// ChecksumError is returned for manifests that fail verification, or that
+// are downloaded without a known checksum to verify them against.
+type ChecksumError struct {
+	// File is the manifest file, crds.yaml or olm.yaml.
+	File string
+	// Version is the OLM release of the manifest.
+	Version string
+	// Expected is the checksum the manifest should have, empty when none is known.
+	Expected string
+	// Actual is the checksum of the manifest.
+	Actual string
+	// Trusted is set when Expected is the checksum the manifest had when it
+	// was first downloaded, rather than a known one.
+	Trusted bool
+}
+
+func (e *ChecksumError) Error() string {
+	if e.Expected == "" {
+		return fmt.Sprintf("no checksum is known to verify %s of OLM %s: review the manifest and set "+
+			"its sha256 checksum %s as the expected checksum", e.File, e.Version, e.Actual)
+	}
+	if e.Trusted {
+		return fmt.Sprintf("%s of OLM %s changed since it was first downloaded: expected sha256 checksum %s, "+
+			"got %s. Review the manifest and set its checksum as the expected checksum to accept it",
+			e.File, e.Version, e.Expected, e.Actual)
+	}
+	return fmt.Sprintf("%s of OLM %s failed verification: expected sha256 checksum %s, got %s",
+		e.File, e.Version, e.Expected, e.Actual)
+}
+
+// fetchResources returns the upstream CRDs and other resources of version.
+func (c Client) fetchResources(ctx context.Context, version string) ([]unstructured.Unstructured, []unstructured.Unstructured, error) {
+	return c.fetchManifests(ctx, InstallOptions{Version: version})
+}
+
+// fetchManifests returns the verified CRDs and other resources of the
+// installation described by opts, before they're customized.
+func (c Client) fetchManifests(ctx context.Context, opts InstallOptions) ([]unstructured.Unstructured, []unstructured.Unstructured, error) {
+	c.Logger().Debug(ctx, "Fetching OLM manifests", map[string]interface{}{"version": opts.Version})
+	crdResources, err := c.fetchManifest(ctx, opts.Version, crdsManifest, opts.Manifests.CRDs, opts.Checksums.CRDs, opts.TrustOnFirstUse)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the CRD manifests: %w", err)
+	}
+	olmResources, err := c.fetchManifest(ctx, opts.Version, olmManifest, opts.Manifests.OLM, opts.Checksums.OLM, opts.TrustOnFirstUse)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the OLM manifests: %w", err)
+	}
+	return crdResources, olmResources, nil
+}
+
+// fetchManifest reads the manifest file of version from source, see
+// ManifestSources, and verifies it before decoding its resources. Downloaded
+// manifests must match checksum or, for upstream releases, the embedded
+// checksum of the release. Without either, they're refused, unless trust is
+// set and the cache is enabled: they're then trusted on first use, the
+// checksum of the first download is kept in the cache and later downloads
+// must match it. Local manifests are only verified when checksum is set.
+func (c Client) fetchManifest(ctx context.Context, version, file, source, checksum string, trust bool) ([]unstructured.Unstructured, error) {
+	log := c.Logger()
+	version = CanonicalVersion(version)
+
+	var data []byte
+	var err error
+	url := ""
+	switch {
+	case source == "":
+		if known, ok := olmmanifests.Checksum(version, file); ok && checksum == "" {
+			checksum = known
+		}
+		// If the manifests for the requested version are saved as bindata in SDK, use
+		// them instead of fetching them from github.
+		if olmmanifests.HasVersion(version) {
+			log.Debug(ctx, "Using locally stored resource manifests", map[string]interface{}{"version": version})
+			data, err = olmmanifests.Asset(filepath.Join(bindataManifestPath, version+"-"+file))
+		} else {
+			resolvedVersion := formatVersion(version)
+			log.Info(ctx, "Downloading OLM manifests", map[string]interface{}{"version": resolvedVersion, "file": file})
+			url = c.manifestURL(resolvedVersion, file)
+		}
+	case IsRemoteManifest(source):
+		log.Info(ctx, "Downloading OLM manifests", map[string]interface{}{"url": source})
+		url = source
+	case isInlineManifest(source):
+		log.Debug(ctx, "Using inline OLM manifests", map[string]interface{}{"file": file})
+		data = []byte(source)
+	default:
+		log.Debug(ctx, "Reading OLM manifests", map[string]interface{}{"path": source})
+		data, err = os.ReadFile(source)
+	}
+
+	// Without the cache, nothing would tell a later download from the first one
+	trusted := false
+	if url != "" {
+		if checksum == "" && trust && c.CacheDir != "" {
+			checksum, trusted = c.trustedChecksum(url), true
+		}
+		data, err = c.downloadCached(ctx, url, checksum)
+		if err == nil && trusted && checksum == "" {
+			if checksum, err = c.trustOnFirstUse(ctx, url, data); err != nil {
+				err = fmt.Errorf("failed to keep the checksum of the first download: %w", err)
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	actual := sha256Hex(data)
+	if (checksum != "" && !strings.EqualFold(strings.TrimPrefix(checksum, "sha256:"), actual)) ||
+		(checksum == "" && url != "") {
+		return nil, &ChecksumError{File: file, Version: version, Expected: checksum, Actual: actual, Trusted: trusted}
+	}
+	return decodeResources(bytes.NewReader(data))
+}
+
+// IsRemoteManifest reports whether source, see ManifestSources, is a URL the
+// manifests are downloaded from.
+func IsRemoteManifest(source string) bool {
+	return strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://")
+}
+
+// isInlineManifest reports whether source holds the manifests themselves
+// rather than naming a file: YAML documents span several lines and JSON
+// starts with a brace or bracket.
+func isInlineManifest(source string) bool {
+	trimmed := strings.TrimSpace(source)
+	return strings.Contains(trimmed, "\n") || strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "[")
+}
@@ -0,0 +1,185 @@
+package installer
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+const testManifest = `apiVersion: v1
+kind: Namespace
+metadata:
+  name: olm
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: olm-operator-serviceaccount
+  namespace: olm
+`
+
+var _ = Describe("fetchManifests", func() {
+	var (
+		c      Client
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/olm.yaml" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(testManifest))
+		}))
+		c = Client{Client: &olmresourceclient.Client{}, HTTPClient: *server.Client()}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	names := func(opts InstallOptions) []string {
+		_, resources, err := c.fetchManifests(context.TODO(), opts)
+		Expect(err).NotTo(HaveOccurred())
+		var names []string
+		for _, r := range resources {
+			names = append(names, r.GetKind()+"/"+r.GetName())
+		}
+		return names
+	}
+	expected := []string{"Namespace/olm", "ServiceAccount/olm-operator-serviceaccount"}
+
+	It("reads inline manifests", func() {
+		Expect(names(InstallOptions{Version: "0.26.0", Manifests: ManifestSources{OLM: testManifest}})).To(Equal(expected))
+	})
+
+	It("reads manifest files", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "olm.yaml")
+		Expect(os.WriteFile(path, []byte(testManifest), 0o600)).To(Succeed())
+		Expect(names(InstallOptions{Version: "0.26.0", Manifests: ManifestSources{OLM: path}})).To(Equal(expected))
+	})
+
+	It("downloads manifests from URLs", func() {
+		Expect(names(InstallOptions{
+			Version:   "0.26.0",
+			Manifests: ManifestSources{OLM: server.URL + "/olm.yaml"},
+			Checksums: ManifestChecksums{OLM: "sha256:" + testManifestChecksum()},
+		})).To(Equal(expected))
+	})
+
+	It("keeps the upstream manifests that aren't replaced", func() {
+		crds, _, err := c.fetchManifests(context.TODO(), InstallOptions{
+			Version:   "0.26.0",
+			Manifests: ManifestSources{OLM: testManifest},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(crds).NotTo(BeEmpty())
+	})
+
+	It("reports a missing file", func() {
+		_, _, err := c.fetchManifests(context.TODO(), InstallOptions{
+			Version:   "0.26.0",
+			Manifests: ManifestSources{CRDs: "does-not-exist.yaml"},
+		})
+		Expect(err).To(MatchError(ContainSubstring("failed to read the CRD manifests")))
+	})
+})
+
+var _ = Describe("manifest verification", func() {
+	var (
+		c      Client
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(testManifest))
+		}))
+		c = Client{Client: &olmresourceclient.Client{}, HTTPClient: *server.Client(), BaseDownloadURL: server.URL}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	checksumError := func(err error) *ChecksumError {
+		var checksumErr *ChecksumError
+		Expect(errors.As(err, &checksumErr)).To(BeTrue(), "expected a checksum error, got %v", err)
+		return checksumErr
+	}
+
+	It("verifies the bundled manifests against the embedded checksums", func() {
+		_, _, err := c.fetchManifests(context.TODO(), InstallOptions{Version: "0.26.0"})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("refuses downloads without a known checksum", func() {
+		c.CacheDir = GinkgoT().TempDir()
+		_, _, err := c.fetchManifests(context.TODO(), InstallOptions{Version: "0.99.0"})
+		checksumErr := checksumError(err)
+		Expect(checksumErr.File).To(Equal("crds.yaml"))
+		Expect(checksumErr.Expected).To(BeEmpty())
+		Expect(checksumErr.Actual).To(Equal(testManifestChecksum()))
+		Expect(err).To(MatchError(ContainSubstring(testManifestChecksum())))
+	})
+
+	It("refuses to trust downloads on first use without a cache", func() {
+		_, _, err := c.fetchManifests(context.TODO(), InstallOptions{Version: "0.99.0", TrustOnFirstUse: true})
+		Expect(checksumError(err).Expected).To(BeEmpty())
+	})
+
+	It("trusts downloads without a known checksum on first use when asked to", func() {
+		c.CacheDir = GinkgoT().TempDir()
+		_, resources, err := c.fetchManifests(context.TODO(), InstallOptions{Version: "0.99.0", TrustOnFirstUse: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(HaveLen(2))
+		Expect(c.trustedChecksum(c.manifestURL("v0.99.0", olmManifest))).To(Equal(testManifestChecksum()))
+	})
+
+	It("rejects downloads that changed since they were trusted", func() {
+		c.CacheDir = GinkgoT().TempDir()
+		url := c.manifestURL("v0.99.0", crdsManifest)
+		Expect(writeCacheFile(c.trustPath(url), []byte(strings.Repeat("0", 64)+"\n"))).To(Succeed())
+		_, _, err := c.fetchManifests(context.TODO(), InstallOptions{Version: "0.99.0", TrustOnFirstUse: true})
+		checksumErr := checksumError(err)
+		Expect(checksumErr.File).To(Equal("crds.yaml"))
+		Expect(checksumErr.Trusted).To(BeTrue())
+		Expect(checksumErr.Actual).To(Equal(testManifestChecksum()))
+	})
+
+	It("accepts downloads matching the expected checksums", func() {
+		_, resources, err := c.fetchManifests(context.TODO(), InstallOptions{
+			Version:   "0.99.0",
+			Checksums: ManifestChecksums{CRDs: testManifestChecksum(), OLM: testManifestChecksum()},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resources).To(HaveLen(2))
+	})
+
+	It("rejects manifests that don't match the expected checksum", func() {
+		_, _, err := c.fetchManifests(context.TODO(), InstallOptions{
+			Version:   "0.26.0",
+			Manifests: ManifestSources{OLM: testManifest},
+			Checksums: ManifestChecksums{OLM: "sha256:" + strings.Repeat("0", 64)},
+		})
+		Expect(err).To(MatchError(ContainSubstring("olm.yaml of OLM 0.26.0 failed verification")))
+		Expect(checksumError(err).Actual).To(Equal(testManifestChecksum()))
+	})
+})
+
+func testManifestChecksum() string {
+	sum := sha256.Sum256([]byte(testManifest))
+	return hex.EncodeToString(sum[:])
+}
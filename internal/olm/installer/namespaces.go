@@ -0,0 +1,124 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+
+	olmapiv1 "github.com/operator-framework/api/pkg/operators/v1"
+	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// namespaceFlags are the flags of the OLM binaries that name the OLM
+// namespace, which is also the global catalog namespace.
+var namespaceFlags = map[string]struct{}{
+	"namespace":                {},
+	"global-namespace":         {},
+	"global-catalog-namespace": {},
+}
+
+// relocateNamespaces moves the upstream OLM resources from the namespaces
+// they're released in to the namespaces in mapping. Besides the namespace of
+// every resource, this renames the Namespace objects and rewrites the
+// namespaces referenced by RoleBinding subjects, OperatorGroup targets and
+// the namespace flags of the OLM containers.
+func relocateNamespaces(resources []unstructured.Unstructured, mapping map[string]string) error {
+	rename := func(namespace string) string {
+		if renamed, ok := mapping[namespace]; ok {
+			return renamed
+		}
+		return namespace
+	}
+
+	for i := range resources {
+		r := &resources[i]
+		if ns := r.GetNamespace(); ns != "" {
+			r.SetNamespace(rename(ns))
+		}
+
+		var err error
+		switch r.GetKind() {
+		case "Namespace":
+			r.SetName(rename(r.GetName()))
+		case "ClusterRoleBinding", "RoleBinding":
+			err = updateNestedMaps(r.Object, func(subject map[string]interface{}) {
+				if ns, ok := subject["namespace"].(string); ok {
+					subject["namespace"] = rename(ns)
+				}
+			}, "subjects")
+		case olmapiv1.OperatorGroupKind:
+			targets, found, nerr := unstructured.NestedStringSlice(r.Object, "spec", "targetNamespaces")
+			if nerr != nil || !found {
+				err = nerr
+				break
+			}
+			for j := range targets {
+				targets[j] = rename(targets[j])
+			}
+			err = unstructured.SetNestedStringSlice(r.Object, targets, "spec", "targetNamespaces")
+		case "Deployment":
+			err = updateNestedMaps(r.Object, func(container map[string]interface{}) {
+				renameNamespaceFlags(container, rename)
+			}, "spec", "template", "spec", "containers")
+		case olmapiv1alpha1.ClusterServiceVersionKind:
+			err = updateNestedMaps(r.Object, func(deployment map[string]interface{}) {
+				_ = updateNestedMaps(deployment, func(container map[string]interface{}) {
+					renameNamespaceFlags(container, rename)
+				}, "spec", "template", "spec", "containers")
+			}, "spec", "install", "spec", "deployments")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to relocate %s %q: %v", r.GetKind(), r.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// updateNestedMaps calls update for every map in the slice at fields of obj.
+func updateNestedMaps(obj map[string]interface{}, update func(map[string]interface{}), fields ...string) error {
+	items, found, err := unstructured.NestedSlice(obj, fields...)
+	if err != nil || !found {
+		return err
+	}
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			update(m)
+		}
+	}
+	return unstructured.SetNestedSlice(obj, items, fields...)
+}
+
+// renameNamespaceFlags renames the values of the namespace flags in the
+// command and args of container.
+func renameNamespaceFlags(container map[string]interface{}, rename func(string) string) {
+	rewriteFlags(container, namespaceFlags, rename)
+}
+
+// rewriteFlags rewrites the values of flags in the command and args of
+// container, in both the "--flag value" and the "--flag=value" form.
+func rewriteFlags(container map[string]interface{}, flags map[string]struct{}, rewrite func(string) string) {
+	for _, field := range []string{"command", "args"} {
+		args, ok := container[field].([]interface{})
+		if !ok {
+			continue
+		}
+		for i := 0; i < len(args); i++ {
+			arg, ok := args[i].(string)
+			if !ok || !strings.HasPrefix(arg, "-") {
+				continue
+			}
+			name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+			if _, ok := flags[name]; !ok {
+				continue
+			}
+			if hasValue {
+				args[i] = strings.TrimSuffix(arg, value) + rewrite(value)
+			} else if i+1 < len(args) {
+				if value, ok := args[i+1].(string); ok {
+					args[i+1] = rewrite(value)
+				}
+				i++
+			}
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package installer
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("relocateNamespaces", func() {
+	var resources []unstructured.Unstructured
+
+	BeforeEach(func() {
+		var err error
+		resources, err = getPackagedManifests(filepath.Join(bindataManifestPath, "0.26.0-olm.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		resources, err = InstallOptions{Namespace: "olm-system", OperatorsNamespace: "global-ops"}.customize(resources)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	find := func(kind, name string) unstructured.Unstructured {
+		for _, r := range resources {
+			if r.GetKind() == kind && r.GetName() == name {
+				return r
+			}
+		}
+		Fail("resource not found: " + kind + "/" + name)
+		return unstructured.Unstructured{}
+	}
+
+	It("moves every namespaced resource", func() {
+		for _, r := range resources {
+			Expect(r.GetNamespace()).To(BeElementOf("", "olm-system", "global-ops"), r.GetKind()+"/"+r.GetName())
+		}
+		catalog := find("CatalogSource", "operatorhubio-catalog")
+		Expect(catalog.GetNamespace()).To(Equal("olm-system"))
+		group := find("OperatorGroup", "global-operators")
+		Expect(group.GetNamespace()).To(Equal("global-ops"))
+	})
+
+	It("renames the namespaces", func() {
+		find("Namespace", "olm-system")
+		find("Namespace", "global-ops")
+	})
+
+	It("rewrites references to the namespaces", func() {
+		binding := find("ClusterRoleBinding", "olm-operator-binding-olm")
+		subjects, _, _ := unstructured.NestedSlice(binding.Object, "subjects")
+		Expect(subjects[0]).To(HaveKeyWithValue("namespace", "olm-system"))
+
+		group := find("OperatorGroup", "olm-operators")
+		targets, _, _ := unstructured.NestedStringSlice(group.Object, "spec", "targetNamespaces")
+		Expect(targets).To(Equal([]string{"olm-system"}))
+
+		catalog := find("Deployment", "catalog-operator")
+		containers, _, _ := unstructured.NestedSlice(catalog.Object, "spec", "template", "spec", "containers")
+		Expect(containers[0].(map[string]interface{})["args"]).To(ContainElements("--namespace", "olm-system"))
+		Expect(containers[0].(map[string]interface{})["args"]).NotTo(ContainElement("olm"))
+
+		csv := find("ClusterServiceVersion", "packageserver")
+		deployments, _, _ := unstructured.NestedSlice(csv.Object, "spec", "install", "spec", "deployments")
+		containers, _, _ = unstructured.NestedSlice(deployments[0].(map[string]interface{}),
+			"spec", "template", "spec", "containers")
+		Expect(containers[0].(map[string]interface{})["command"]).To(ContainElements("--global-namespace", "olm-system"))
+	})
+
+	It("rewrites flags in the --flag=value form", func() {
+		container := map[string]interface{}{
+			"args": []interface{}{"--global-catalog-namespace=olm", "--writeStatusName", "olm"},
+		}
+		renameNamespaceFlags(container, func(ns string) string {
+			if ns == "olm" {
+				return "olm-system"
+			}
+			return ns
+		})
+		Expect(container["args"]).To(Equal([]interface{}{"--global-catalog-namespace=olm-system", "--writeStatusName", "olm"}))
+	})
+})
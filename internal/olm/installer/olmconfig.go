@@ -0,0 +1,79 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/blang/semver/v4"
+	olmapiv1 "github.com/operator-framework/api/pkg/operators/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// OLMConfigName is the name of the cluster-wide OLMConfig OLM is released with.
+const OLMConfigName = "cluster"
+
+// OLMConfigMinVersion is the first OLM release with an OLMConfig.
+const OLMConfigMinVersion = "0.24.0"
+
+// SupportsOLMConfig reports whether the OLM release version is released with
+// an OLMConfig. Versions that aren't semver are assumed to be.
+func SupportsOLMConfig(version string) bool {
+	sv, err := semver.ParseTolerant(version)
+	return err != nil || sv.GTE(semver.MustParse(OLMConfigMinVersion))
+}
+
+// OLMConfigOptions configures the cluster OLMConfig. The zero value keeps
+// the defaults of OLM.
+type OLMConfigOptions struct {
+	// DisableCopiedCSVs stops OLM from copying the CSVs of operators
+	// watching all namespaces into every namespace.
+	DisableCopiedCSVs *bool
+	// PackageServerSyncInterval replaces how often packageserver checks the
+	// CatalogSources, as a duration such as "60m".
+	PackageServerSyncInterval string
+}
+
+// customizeOLMConfig applies opts to the OLMConfig in resources.
+func customizeOLMConfig(resources []unstructured.Unstructured, opts OLMConfigOptions) error {
+	if opts.DisableCopiedCSVs == nil && opts.PackageServerSyncInterval == "" {
+		return nil
+	}
+	for i := range resources {
+		r := &resources[i]
+		if r.GetKind() != "OLMConfig" || r.GetName() != OLMConfigName {
+			continue
+		}
+		var err error
+		if opts.DisableCopiedCSVs != nil {
+			err = unstructured.SetNestedField(r.Object, *opts.DisableCopiedCSVs, "spec", "features", "disableCopiedCSVs")
+		}
+		if opts.PackageServerSyncInterval != "" && err == nil {
+			err = unstructured.SetNestedField(r.Object, opts.PackageServerSyncInterval,
+				"spec", "features", "packageServerSyncInterval")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to customize OLMConfig %q: %v", r.GetName(), err)
+		}
+		return nil
+	}
+	return errors.New("the OLM manifests have no OLMConfig to configure, it's released with OLM 0.24 and later")
+}
+
+// GetOLMConfig returns the configuration of the cluster OLMConfig, the zero
+// value when there is none.
+func (c Client) GetOLMConfig(ctx context.Context) (OLMConfigOptions, error) {
+	var opts OLMConfigOptions
+	config := newObject(olmapiv1.GroupVersion.WithKind("OLMConfig"), "", OLMConfigName)
+	if err := c.KubeClient.Get(ctx, types.NamespacedName{Name: OLMConfigName}, &config); isGone(err) {
+		return opts, nil
+	} else if err != nil {
+		return opts, fmt.Errorf("failed to get OLMConfig %q: %v", OLMConfigName, err)
+	}
+	if disabled, found, _ := unstructured.NestedBool(config.Object, "spec", "features", "disableCopiedCSVs"); found {
+		opts.DisableCopiedCSVs = &disabled
+	}
+	opts.PackageServerSyncInterval, _, _ = unstructured.NestedString(config.Object, "spec", "features", "packageServerSyncInterval")
+	return opts, nil
+}
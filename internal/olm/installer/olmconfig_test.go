@@ -0,0 +1,73 @@
+package installer
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+var _ = Describe("OLMConfig", func() {
+	var resources []unstructured.Unstructured
+
+	BeforeEach(func() {
+		var err error
+		resources, err = getPackagedManifests(filepath.Join(bindataManifestPath, "0.26.0-olm.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	olmConfig := func(resources []unstructured.Unstructured) unstructured.Unstructured {
+		configs := filterResources(resources, func(r unstructured.Unstructured) bool {
+			return r.GetKind() == "OLMConfig"
+		})
+		Expect(configs).To(HaveLen(1))
+		return configs[0]
+	}
+
+	It("keeps the upstream OLMConfig by default", func() {
+		Expect(customizeOLMConfig(resources, OLMConfigOptions{})).To(Succeed())
+		Expect(olmConfig(resources).Object).NotTo(HaveKey("spec"))
+	})
+
+	It("configures the OLMConfig", func() {
+		Expect(customizeOLMConfig(resources, OLMConfigOptions{
+			DisableCopiedCSVs:         ptr.To(true),
+			PackageServerSyncInterval: "30m",
+		})).To(Succeed())
+		Expect(olmConfig(resources).Object["spec"]).To(Equal(map[string]interface{}{
+			"features": map[string]interface{}{
+				"disableCopiedCSVs":         true,
+				"packageServerSyncInterval": "30m",
+			},
+		}))
+	})
+
+	It("fails on manifests without an OLMConfig", func() {
+		resources = filterResources(resources, func(r unstructured.Unstructured) bool {
+			return r.GetKind() != "OLMConfig"
+		})
+		Expect(customizeOLMConfig(resources, OLMConfigOptions{DisableCopiedCSVs: ptr.To(false)})).
+			To(MatchError(ContainSubstring("no OLMConfig")))
+	})
+
+	It("reads the OLMConfig on the cluster", func() {
+		live := olmConfig(resources)
+		Expect(unstructured.SetNestedField(live.Object, true, "spec", "features", "disableCopiedCSVs")).To(Succeed())
+		c := Client{Client: &olmresourceclient.Client{KubeClient: fake.NewClientBuilder().WithObjects(&live).Build()}}
+
+		opts, err := c.GetOLMConfig(context.TODO())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(opts).To(Equal(OLMConfigOptions{DisableCopiedCSVs: ptr.To(true)}))
+	})
+
+	It("reads no configuration without an OLMConfig", func() {
+		c := Client{Client: &olmresourceclient.Client{KubeClient: fake.NewClientBuilder().Build()}}
+		Expect(c.GetOLMConfig(context.TODO())).To(Equal(OLMConfigOptions{}))
+	})
+})
@@ -0,0 +1,68 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Operators are the operators OLM manages on a cluster.
+type Operators struct {
+	// Subscriptions are the subscriptions in all namespaces.
+	Subscriptions []types.NamespacedName
+	// CSVs are the ClusterServiceVersions in all namespaces, without the
+	// copies OLM makes for operators watching several namespaces.
+	CSVs []types.NamespacedName
+}
+
+// Empty reports whether no operators are managed by OLM.
+func (o Operators) Empty() bool {
+	return len(o.Subscriptions) == 0 && len(o.CSVs) == 0
+}
+
+func (o Operators) String() string {
+	names := make([]string, 0, len(o.Subscriptions)+len(o.CSVs))
+	for _, key := range o.Subscriptions {
+		names = append(names, "subscription/"+key.Name+" in "+key.Namespace)
+	}
+	for _, key := range o.CSVs {
+		names = append(names, "clusterserviceversion/"+key.Name+" in "+key.Namespace)
+	}
+	return strings.Join(names, ", ")
+}
+
+// ListOperators returns the operators managed by the OLM installation
+// described by opts, excluding OLM's own packageserver CSV. Uninstalling OLM
+// deletes its CRDs, and with them every one of these objects.
+func (c Client) ListOperators(ctx context.Context, opts InstallOptions) (Operators, error) {
+	var operators Operators
+
+	subscriptions := olmapiv1alpha1.SubscriptionList{}
+	if err := c.KubeClient.List(ctx, &subscriptions); meta.IsNoMatchError(err) {
+		return operators, nil
+	} else if err != nil {
+		return operators, fmt.Errorf("failed to list subscriptions: %v", err)
+	}
+	for _, sub := range subscriptions.Items {
+		operators.Subscriptions = append(operators.Subscriptions,
+			types.NamespacedName{Namespace: sub.Namespace, Name: sub.Name})
+	}
+
+	csvs := olmapiv1alpha1.ClusterServiceVersionList{}
+	if err := c.KubeClient.List(ctx, &csvs); meta.IsNoMatchError(err) {
+		return operators, nil
+	} else if err != nil {
+		return operators, fmt.Errorf("failed to list ClusterServiceVersions: %v", err)
+	}
+	for _, csv := range csvs.Items {
+		if olmapiv1alpha1.IsCopied(&csv) || (csv.Namespace == opts.olmNamespace() && csv.Name == packageServerName) {
+			continue
+		}
+		operators.CSVs = append(operators.CSVs, types.NamespacedName{Namespace: csv.Namespace, Name: csv.Name})
+	}
+	return operators, nil
+}
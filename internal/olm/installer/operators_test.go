@@ -0,0 +1,48 @@
+package installer
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+var _ = Describe("ListOperators", func() {
+	csv := func(namespace, name string, labels map[string]string) *olmapiv1alpha1.ClusterServiceVersion {
+		return &olmapiv1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name, Labels: labels},
+		}
+	}
+
+	It("lists the subscriptions and CSVs of all namespaces", func() {
+		c := Client{Client: &olmresourceclient.Client{KubeClient: fake.NewClientBuilder().WithObjects(
+			&olmapiv1alpha1.Subscription{ObjectMeta: metav1.ObjectMeta{Namespace: "operators", Name: "cert-manager"}},
+			csv("operators", "cert-manager.v1.14.0", nil),
+			csv("default", "cert-manager.v1.14.0", map[string]string{olmapiv1alpha1.CopiedLabelKey: "operators"}),
+			csv("olm", packageServerName, nil),
+		).Build()}}
+
+		operators, err := c.ListOperators(context.TODO(), InstallOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(operators.Subscriptions).To(ConsistOf(types.NamespacedName{Namespace: "operators", Name: "cert-manager"}))
+		Expect(operators.CSVs).To(ConsistOf(types.NamespacedName{Namespace: "operators", Name: "cert-manager.v1.14.0"}))
+		Expect(operators.String()).To(Equal("subscription/cert-manager in operators, " +
+			"clusterserviceversion/cert-manager.v1.14.0 in operators"))
+	})
+
+	It("finds no operators on a cluster without any", func() {
+		c := Client{Client: &olmresourceclient.Client{KubeClient: fake.NewClientBuilder().WithObjects(
+			csv("olm-system", packageServerName, nil),
+		).Build()}}
+
+		operators, err := c.ListOperators(context.TODO(), InstallOptions{Namespace: "olm-system"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(operators.Empty()).To(BeTrue())
+	})
+})
@@ -0,0 +1,104 @@
+package installer
+
+import (
+	"reflect"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// InstallOptions describes an OLM installation: the release to install and
+// how its manifests are customized before they're applied. The same options
+// must be used to check the status of or to uninstall the installation.
+type InstallOptions struct {
+	// Version is the OLM release, with or without a "v" prefix.
+	Version string
+	// Namespace is where OLM itself is installed, defaults to DefaultOLMNamespace.
+	Namespace string
+	// OperatorsNamespace is the namespace of the global OperatorGroup,
+	// defaults to DefaultOperatorsNamespace.
+	OperatorsNamespace string
+	// AdoptExisting takes over the objects of an OLM installation already on
+	// the cluster, as long as they match Version, instead of failing the install.
+	AdoptExisting bool
+	// StripFinalizers removes the finalizers that keep OLM objects from being
+	// deleted on uninstall, instead of failing it.
+	StripFinalizers bool
+	// Images overrides the images of the OLM components.
+	Images ImageOverrides
+	// OLMOperator tunes the olm-operator deployment.
+	OLMOperator DeploymentOptions
+	// CatalogOperator tunes the catalog-operator deployment.
+	CatalogOperator DeploymentOptions
+	// PackageServer tunes the packageserver deployment.
+	PackageServer DeploymentOptions
+	// DefaultCatalog customizes or disables the default CatalogSource.
+	DefaultCatalog CatalogOptions
+	// OLMConfig configures the cluster OLMConfig.
+	OLMConfig OLMConfigOptions
+	// Manifests replaces the upstream manifests of Version.
+	Manifests ManifestSources
+	// Checksums are the expected checksums of the manifests.
+	Checksums ManifestChecksums
+	// TrustOnFirstUse accepts downloaded manifests without a known checksum
+	// as long as they match their first download, whose checksum is kept in
+	// the manifest cache. They're refused when the cache is disabled.
+	TrustOnFirstUse bool
+}
+
+// SameInstallation reports whether a and b describe the same OLM
+// installation, so that nothing has to be applied to move from one to the
+// other. AdoptExisting only matters on install, StripFinalizers on
+// uninstall and TrustOnFirstUse when manifests are fetched, they are not
+// compared.
+func SameInstallation(a, b InstallOptions) bool {
+	if !SameVersion(a.Version, b.Version) {
+		return false
+	}
+	a.Version, b.Version = "", ""
+	a.AdoptExisting, b.AdoptExisting = false, false
+	a.StripFinalizers, b.StripFinalizers = false, false
+	a.TrustOnFirstUse, b.TrustOnFirstUse = false, false
+	return reflect.DeepEqual(a, b)
+}
+
+// olmNamespace returns the namespace OLM is installed in.
+func (o InstallOptions) olmNamespace() string {
+	if o.Namespace == "" {
+		return DefaultOLMNamespace
+	}
+	return o.Namespace
+}
+
+// operatorsNamespace returns the namespace of the global OperatorGroup.
+func (o InstallOptions) operatorsNamespace() string {
+	if o.OperatorsNamespace == "" {
+		return DefaultOperatorsNamespace
+	}
+	return o.OperatorsNamespace
+}
+
+// customize applies the options to the upstream OLM resources and returns
+// the resulting resources.
+func (o InstallOptions) customize(resources []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	resources, err := customizeCatalog(resources, o.DefaultCatalog)
+	if err != nil {
+		return nil, err
+	}
+	if err := customizeOLMConfig(resources, o.OLMConfig); err != nil {
+		return nil, err
+	}
+	labelManaged(resources)
+	if err := overrideImages(resources, o.Images); err != nil {
+		return nil, err
+	}
+	if err := tuneDeployments(resources, o); err != nil {
+		return nil, err
+	}
+	if err := relocateNamespaces(resources, map[string]string{
+		DefaultOLMNamespace:       o.olmNamespace(),
+		DefaultOperatorsNamespace: o.operatorsNamespace(),
+	}); err != nil {
+		return nil, err
+	}
+	return resources, nil
+}
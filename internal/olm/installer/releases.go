@@ -0,0 +1,166 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	goversion "github.com/hashicorp/go-version"
+
+	olmmanifests "github.com/kaplan-michael/terraform-provider-olm/internal/bindata/olm"
+)
+
+// DefaultReleasesURL is the GitHub API listing the releases published at
+// DefaultBaseDownloadURL.
+const DefaultReleasesURL = "https://api.github.com/repos/operator-framework/operator-lifecycle-manager/releases"
+
+// maxListedVersions is how many of the newest remote versions a VersionError
+// lists.
+const maxListedVersions = 10
+
+// VersionError is returned by CheckVersion for versions whose manifests are
+// neither bundled with the provider nor published at the release source, and
+// by ResolveVersion for constraints no such version matches.
+type VersionError struct {
+	// Version is the requested version or constraint.
+	Version string
+	// Source is the base URL the manifests were looked up at.
+	Source string
+	// Bundled are the versions bundled with the provider.
+	Bundled []string
+	// Remote are the versions published at the release source, newest first,
+	// or nil when they couldn't be listed.
+	Remote []string
+}
+
+func (e *VersionError) Error() string {
+	msg := fmt.Sprintf("OLM %s is neither bundled with the provider nor published at %s. Bundled versions: %s",
+		e.Version, e.Source, strings.Join(e.Bundled, ", "))
+	if IsVersionConstraint(e.Version) {
+		msg = fmt.Sprintf("no OLM release bundled with the provider or published at %s matches %q. Bundled versions: %s",
+			e.Source, e.Version, strings.Join(e.Bundled, ", "))
+	}
+	if len(e.Remote) > 0 {
+		remote := e.Remote
+		if len(remote) > maxListedVersions {
+			remote = remote[:maxListedVersions]
+		}
+		msg += fmt.Sprintf("; latest published versions: %s", strings.Join(remote, ", "))
+	}
+	return msg
+}
+
+// checkVersionTimeout bounds the request CheckVersion sends, so an
+// unreachable release source doesn't stall the plan.
+var checkVersionTimeout = 10 * time.Second
+
+// CheckVersion reports whether the manifests of version are available, either
+// bundled with the provider, downloaded before, or published at the release
+// source. The release source is asked once, with a HEAD request bounded by
+// checkVersionTimeout. It returns a *VersionError when the release source
+// doesn't have them, and other errors when it can't be reached.
+func (c Client) CheckVersion(ctx context.Context, version string) error {
+	version = CanonicalVersion(version)
+	if olmmanifests.HasVersion(version) {
+		return nil
+	}
+	url := c.manifestURL(formatVersion(version), crdsManifest)
+	if c.trustedChecksum(url) != "" {
+		return nil
+	}
+
+	headCtx, cancel := context.WithTimeout(ctx, checkVersionTimeout)
+	defer cancel()
+	resp, err := c.doRequestOnce(headCtx, http.MethodHead, url)
+	if err == nil {
+		resp.Body.Close()
+		return nil
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return err
+	}
+
+	versionErr := &VersionError{Version: version, Source: c.BaseDownloadURL, Bundled: olmmanifests.Versions()}
+	if remote, err := c.RemoteVersions(ctx); err == nil {
+		versionErr.Remote = remote
+	} else {
+		c.Logger().Debug(ctx, "Failed to list the published OLM versions", map[string]interface{}{"error": err.Error()})
+	}
+	return versionErr
+}
+
+// ResolveVersion returns the newest release matching version, which may be
+// LatestVersion or a constraint such as "~> 0.26", among the bundled releases
+// and the published ones. Only the bundled releases are considered when the
+// published ones can't be listed. A single release is returned as-is.
+func (c Client) ResolveVersion(ctx context.Context, version string) (string, error) {
+	if !IsVersionConstraint(version) {
+		return CanonicalVersion(version), nil
+	}
+	constraints, err := versionConstraints(version)
+	if err != nil {
+		return "", ValidateVersion(version)
+	}
+
+	bundled := olmmanifests.Versions()
+	remote, err := c.RemoteVersions(ctx)
+	if err != nil {
+		c.Logger().Warn(ctx, "Resolving the OLM version among the bundled releases only",
+			map[string]interface{}{"version": version, "error": err.Error()})
+	}
+	var newest *goversion.Version
+	for _, name := range append(append([]string{}, bundled...), remote...) {
+		v, err := goversion.NewVersion(name)
+		if err == nil && constraints.Check(v) && (newest == nil || v.GreaterThan(newest)) {
+			newest = v
+		}
+	}
+	if newest == nil {
+		return "", &VersionError{Version: version, Source: c.BaseDownloadURL, Bundled: bundled, Remote: remote}
+	}
+	c.Logger().Debug(ctx, "Resolved the OLM version", map[string]interface{}{"version": version, "resolved": newest.String()})
+	return CanonicalVersion(newest.String()), nil
+}
+
+// RemoteVersions returns the versions of the latest OLM releases published at
+// the release source, newest first. Drafts and pre-releases are left out.
+// Only the GitHub releases of OLM can be listed, not mirrors.
+func (c Client) RemoteVersions(ctx context.Context) ([]string, error) {
+	if c.ReleasesURL == "" {
+		return nil, errors.New("the releases of a manifest mirror can't be listed")
+	}
+	data, err := c.download(ctx, c.ReleasesURL+"?per_page=100")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list OLM releases: %v", err)
+	}
+	var releases []struct {
+		TagName    string `json:"tag_name"`
+		Draft      bool   `json:"draft"`
+		Prerelease bool   `json:"prerelease"`
+	}
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("failed to decode OLM releases: %v", err)
+	}
+
+	var versions []semver.Version
+	for _, release := range releases {
+		if release.Draft || release.Prerelease {
+			continue
+		}
+		if sv, err := semver.ParseTolerant(release.TagName); err == nil && len(sv.Pre) == 0 {
+			versions = append(versions, sv)
+		}
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].GT(versions[j]) })
+	names := make([]string, 0, len(versions))
+	for _, sv := range versions {
+		names = append(names, sv.String())
+	}
+	return names, nil
+}
@@ -0,0 +1,147 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	olmmanifests "github.com/kaplan-michael/terraform-provider-olm/internal/bindata/olm"
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+var _ = Describe("releases", func() {
+	var (
+		c        Client
+		server   *httptest.Server
+		requests []string
+	)
+
+	BeforeEach(func() {
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			switch r.URL.Path {
+			case "/download/v0.27.0/crds.yaml":
+				_, _ = w.Write([]byte("crds"))
+			case "/download/v0.28.0/crds.yaml":
+				w.WriteHeader(http.StatusBadGateway)
+			case "/download/v0.29.0/crds.yaml":
+				time.Sleep(100 * time.Millisecond)
+			case "/releases":
+				_, _ = w.Write([]byte(`[
+					{"tag_name": "v0.28.0-rc.0", "prerelease": true},
+					{"tag_name": "v0.26.0"},
+					{"tag_name": "v0.27.0"},
+					{"tag_name": "v0.29.0", "draft": true}
+				]`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		c = Client{Client: &olmresourceclient.Client{}}
+		Expect(c.SetDownloadOptions(DownloadOptions{BaseURL: server.URL})).To(Succeed())
+		c.ReleasesURL = server.URL + "/releases"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("CheckVersion", func() {
+		It("accepts bundled versions without a request", func() {
+			Expect(c.CheckVersion(context.TODO(), "v0.26.0")).To(Succeed())
+			Expect(requests).To(BeEmpty())
+		})
+
+		It("accepts versions published at the release source", func() {
+			Expect(c.CheckVersion(context.TODO(), "0.27.0")).To(Succeed())
+			Expect(requests).To(Equal([]string{"HEAD /download/v0.27.0/crds.yaml"}))
+		})
+
+		It("accepts versions downloaded before without a request", func() {
+			c.CacheDir = GinkgoT().TempDir()
+			url := c.manifestURL("v0.99.0", crdsManifest)
+			Expect(writeCacheFile(c.trustPath(url), []byte(testManifestChecksum()+"\n"))).To(Succeed())
+			Expect(c.CheckVersion(context.TODO(), "0.99.0")).To(Succeed())
+			Expect(requests).To(BeEmpty())
+		})
+
+		It("asks the release source once", func() {
+			c.Retries = 3
+			err := c.CheckVersion(context.TODO(), "0.28.0")
+			var versionErr *VersionError
+			Expect(err).To(MatchError(ContainSubstring("unexpected status code 502")))
+			Expect(errors.As(err, &versionErr)).To(BeFalse())
+			Expect(requests).To(HaveLen(1))
+		})
+
+		It("gives up on a slow release source", func() {
+			timeout := checkVersionTimeout
+			checkVersionTimeout = 10 * time.Millisecond
+			defer func() { checkVersionTimeout = timeout }()
+			err := c.CheckVersion(context.TODO(), "0.29.0")
+			var versionErr *VersionError
+			Expect(err).To(HaveOccurred())
+			Expect(errors.As(err, &versionErr)).To(BeFalse())
+		})
+
+		It("lists the bundled and published versions of unknown versions", func() {
+			err := c.CheckVersion(context.TODO(), "0.99.0")
+			var versionErr *VersionError
+			Expect(errors.As(err, &versionErr)).To(BeTrue(), "expected a version error, got %v", err)
+			Expect(versionErr.Bundled).To(ContainElement("0.26.0"))
+			Expect(versionErr.Remote).To(Equal([]string{"0.27.0", "0.26.0"}))
+			Expect(err).To(MatchError(ContainSubstring("latest published versions: 0.27.0, 0.26.0")))
+		})
+
+		It("omits the published versions of mirrors", func() {
+			c.ReleasesURL = ""
+			err := c.CheckVersion(context.TODO(), "0.99.0")
+			var versionErr *VersionError
+			Expect(errors.As(err, &versionErr)).To(BeTrue(), "expected a version error, got %v", err)
+			Expect(versionErr.Remote).To(BeNil())
+			Expect(err).NotTo(MatchError(ContainSubstring("published versions")))
+		})
+
+		It("fails on an unreachable release source", func() {
+			server.Close()
+			err := c.CheckVersion(context.TODO(), "0.99.0")
+			var versionErr *VersionError
+			Expect(err).To(HaveOccurred())
+			Expect(errors.As(err, &versionErr)).To(BeFalse())
+		})
+	})
+
+	Describe("ResolveVersion", func() {
+		It("returns single releases as-is", func() {
+			Expect(c.ResolveVersion(context.TODO(), "v0.99.0")).To(Equal("0.99.0"))
+			Expect(requests).To(BeEmpty())
+		})
+
+		It("resolves latest among the bundled and published releases", func() {
+			Expect(c.ResolveVersion(context.TODO(), LatestVersion)).To(Equal("0.27.0"))
+		})
+
+		It("resolves constraints to the newest matching release", func() {
+			Expect(c.ResolveVersion(context.TODO(), "~> 0.25.0")).To(Equal("0.25.0"))
+			Expect(c.ResolveVersion(context.TODO(), ">= 0.24, < 0.27")).To(Equal("0.26.0"))
+		})
+
+		It("resolves among the bundled releases when the published ones can't be listed", func() {
+			c.ReleasesURL = ""
+			Expect(c.ResolveVersion(context.TODO(), LatestVersion)).To(Equal(olmmanifests.Versions()[len(olmmanifests.Versions())-1]))
+		})
+
+		It("fails on constraints no release matches", func() {
+			_, err := c.ResolveVersion(context.TODO(), "~> 1.0")
+			var versionErr *VersionError
+			Expect(errors.As(err, &versionErr)).To(BeTrue(), "expected a version error, got %v", err)
+			Expect(err).To(MatchError(ContainSubstring(`matches "~> 1.0"`)))
+		})
+	})
+})
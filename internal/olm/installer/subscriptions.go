@@ -5,7 +5,6 @@ import (
 	"fmt"
 	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
 	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
-	log "github.com/sirupsen/logrus"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -24,7 +23,8 @@ func (c Client) InstallOperator(ctx context.Context, resources []unstructured.Un
 		}
 	})
 
-	log.Print("Creating subscription resources")
+	log := c.Logger()
+	log.Info(ctx, "Creating subscription resources", phaseField("subscription"))
 	objs := toObjects(subscriptions...)
 	if err := c.DoCreate(ctx, objs...); err != nil {
 		return nil, fmt.Errorf("failed to create subscriptions: %v", err)
@@ -32,15 +32,18 @@ func (c Client) InstallOperator(ctx context.Context, resources []unstructured.Un
 
 	for _, sub := range subscriptions {
 		subscriptionKey := types.NamespacedName{Namespace: sub.GetNamespace(), Name: sub.GetName()}
-		log.Printf("Waiting for subscription/%s to install CSV", subscriptionKey.Name)
+		log.Info(ctx, "Waiting for subscription to install CSV",
+			olmresourceclient.KeyFields(olmapiv1alpha1.SubscriptionKind, subscriptionKey), phaseField("subscription"))
 		csvKey, err := c.getSubscriptionCSV(ctx, subscriptionKey)
 		if err != nil {
-			return nil, fmt.Errorf("subscription/%s failed to install CSV: %v", subscriptionKey.Name, err)
+			return nil, phaseError(ctx, PhaseSubscription,
+				fmt.Errorf("subscription/%s failed to install CSV: %v", subscriptionKey.Name, err))
 		}
-		log.Printf("Waiting for clusterserviceversion/%s to reach 'Succeeded' phase", csvKey.Name)
+		log.Info(ctx, "Waiting for ClusterServiceVersion to reach 'Succeeded' phase",
+			olmresourceclient.KeyFields(olmapiv1alpha1.ClusterServiceVersionKind, csvKey), phaseField("csv"))
 		if err := c.DoCSVWait(ctx, csvKey); err != nil {
-			return nil, fmt.Errorf("clusterserviceversion/%s failed to reach 'Succeeded' phase",
-				csvKey.Name)
+			return nil, phaseError(ctx, PhaseCSV, fmt.Errorf("clusterserviceversion/%s failed to reach 'Succeeded' phase",
+				csvKey.Name))
 		}
 
 	}
@@ -59,14 +62,17 @@ func (c Client) GetSubscriptionStatus(ctx context.Context, resources []unstructu
 		}
 	})
 
+	log := c.Logger()
 	for _, sub := range subscriptions {
 		subscriptionKey := types.NamespacedName{Namespace: sub.GetNamespace(), Name: sub.GetName()}
-		log.Printf("Found CSV for subscription/%s", subscriptionKey.Name)
+		log.Debug(ctx, "Looking up CSV for subscription",
+			olmresourceclient.KeyFields(olmapiv1alpha1.SubscriptionKind, subscriptionKey))
 		csvKey, err := c.getSubscriptionCSV(ctx, subscriptionKey)
 		if err != nil {
 			return nil, fmt.Errorf("Can't find CSV for subscription/%s  %v", subscriptionKey.Name, err)
 		}
-		log.Printf("Check if CSV for subscription/%s is in the 'Succeeded' phase", csvKey.Name)
+		log.Debug(ctx, "Checking if ClusterServiceVersion is in the 'Succeeded' phase",
+			olmresourceclient.KeyFields(olmapiv1alpha1.ClusterServiceVersionKind, csvKey))
 		if err := c.DoCSVWait(ctx, csvKey); err != nil {
 			return nil, fmt.Errorf("clusterserviceversion/%s failed is not in the 'Succeeded' phase, please check the cluster",
 				csvKey.Name)
@@ -143,13 +149,16 @@ func (c Client) UninstallOperator(ctx context.Context, resources []unstructured.
 		subscriptionKey := types.NamespacedName{Namespace: sub.GetNamespace(), Name: sub.GetName()}
 		csvKey, err := c.getSubscriptionCSV(ctx, subscriptionKey)
 		if err != nil {
-			return fmt.Errorf("couln't get subscriptions/%s CSV: %v", subscriptionKey.Name, err)
+			return phaseError(ctx, PhaseSubscription,
+				fmt.Errorf("couln't get subscriptions/%s CSV: %v", subscriptionKey.Name, err))
 		}
 		csv := olmapiv1alpha1.ClusterServiceVersion{}
 		err = c.Client.KubeClient.Get(ctx, csvKey, &csv)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
-				log.Printf("couln't get CSV/%s CSV: %v", csvKey.Name, err)
+				c.Logger().Warn(ctx, "Couldn't get ClusterServiceVersion",
+					olmresourceclient.KeyFields(olmapiv1alpha1.ClusterServiceVersionKind, csvKey),
+					map[string]interface{}{"error": err.Error()})
 				continue
 
 			}
@@ -0,0 +1,43 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// The phases of an install or uninstall that wait on the cluster, as reported by
+// TimeoutError.
+const (
+	PhaseCRDs         = "CRD establishment"
+	PhaseRollout      = "rollout"
+	PhaseSubscription = "subscription"
+	PhaseCSV          = "CSV"
+	PhaseDeletion     = "deletion"
+)
+
+// TimeoutError is returned when the deadline of the context runs out while
+// waiting on the cluster.
+type TimeoutError struct {
+	// Phase is the phase that was waited on, e.g. PhaseRollout.
+	Phase string
+	// Err is the error of the wait.
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("timed out waiting for %s: %v", e.Phase, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// phaseError returns err as a TimeoutError of phase when the deadline of ctx
+// ran out, and err itself otherwise.
+func phaseError(ctx context.Context, phase string, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &TimeoutError{Phase: phase, Err: err}
+	}
+	return err
+}
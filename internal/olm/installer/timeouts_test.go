@@ -0,0 +1,39 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+var _ = Describe("timeouts", func() {
+	It("reports the phase that ran out of time", func() {
+		c := Client{Client: &olmresourceclient.Client{KubeClient: fake.NewClientBuilder().Build()}}
+		subscription := unstructured.Unstructured{}
+		subscription.SetGroupVersionKind(olmapiv1alpha1.SchemeGroupVersion.WithKind(olmapiv1alpha1.SubscriptionKind))
+		subscription.SetNamespace("operators")
+		subscription.SetName("etcd")
+
+		ctx, cancel := context.WithTimeout(context.TODO(), 1500*time.Millisecond)
+		defer cancel()
+		_, err := c.InstallOperator(ctx, []unstructured.Unstructured{subscription})
+
+		var timeoutErr *TimeoutError
+		Expect(errors.As(err, &timeoutErr)).To(BeTrue(), "expected a timeout error, got %v", err)
+		Expect(timeoutErr.Phase).To(Equal(PhaseSubscription))
+		Expect(err).To(MatchError(ContainSubstring("timed out waiting for subscription: subscription/etcd")))
+	})
+
+	It("leaves other errors as they are", func() {
+		err := errors.New("csv failed")
+		Expect(phaseError(context.TODO(), PhaseCSV, err)).To(BeIdenticalTo(err))
+	})
+})
@@ -0,0 +1,342 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blang/semver/v4"
+	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	olmmanifests "github.com/kaplan-michael/terraform-provider-olm/internal/bindata/olm"
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+// packagesAPIServiceName is the aggregated API served by packageserver. OLM
+// only removes it while olm-operator runs, and namespaces can't finish
+// terminating while it is registered but unavailable.
+const packagesAPIServiceName = "v1.packages.operators.coreos.com"
+
+// finalizerGracePeriod is how long deleted objects may take to go away
+// before the finalizers keeping them are reported, or stripped.
+var finalizerGracePeriod = time.Minute
+
+// FinalizerError is returned by UninstallVersion when deleted objects are
+// kept around by finalizers that nothing removes.
+type FinalizerError struct {
+	// Objects are the objects stuck deleting.
+	Objects []StuckObject
+}
+
+// StuckObject is an object whose deletion waits on finalizers.
+type StuckObject struct {
+	Kind       string
+	Key        types.NamespacedName
+	Finalizers []string
+}
+
+func (o StuckObject) String() string {
+	name := o.Key.Name
+	if o.Key.Namespace != "" {
+		name = o.Key.Namespace + "/" + name
+	}
+	return fmt.Sprintf("%s %s (finalizers: %s)", strings.ToLower(o.Kind), name, strings.Join(o.Finalizers, ", "))
+}
+
+func (e *FinalizerError) Error() string {
+	objects := make([]string, 0, len(e.Objects))
+	for _, o := range e.Objects {
+		objects = append(objects, o.String())
+	}
+	return "deletion is blocked by finalizers: " + strings.Join(objects, "; ")
+}
+
+// UninstallVersion removes the OLM installation described by opts. Objects
+// are deleted in an order that lets OLM clean up after itself: the custom
+// resources of the OLM CRDs while OLM still runs, then the packageserver CSV
+// and its APIService, the deployments and other resources, the CRDs, and the
+// namespaces last. Objects kept by finalizers fail the uninstall with a
+// FinalizerError, unless opts.StripFinalizers is set.
+func (c Client) UninstallVersion(ctx context.Context, opts InstallOptions) error {
+	crds, resources, err := c.installedResources(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to get resources: %w", err)
+	}
+	objs := toObjects(append(crds, resources...)...)
+
+	status := c.GetObjectsStatus(ctx, objs...)
+	installed, err := status.HasInstalledResources()
+	if !installed && err == nil {
+		return olmresourceclient.ErrOLMNotInstalled
+	}
+
+	log := c.Logger()
+	namespace := opts.olmNamespace()
+	versionFields := map[string]interface{}{"version": opts.Version, "namespace": namespace}
+	isPackageServerCSV := func(r unstructured.Unstructured) bool {
+		return r.GetKind() == olmapiv1alpha1.ClusterServiceVersionKind &&
+			r.GetNamespace() == namespace && r.GetName() == packageServerName
+	}
+
+	log.Info(ctx, "Uninstalling OLM resources", versionFields, phaseField("uninstall"))
+	customResources, err := c.listCustomResources(ctx, crds)
+	if err != nil {
+		return err
+	}
+	steps := []struct {
+		name    string
+		objects []unstructured.Unstructured
+	}{
+		{"custom resources", filterResources(customResources, func(r unstructured.Unstructured) bool {
+			return !isPackageServerCSV(r)
+		})},
+		{"packageserver", []unstructured.Unstructured{
+			newObject(olmapiv1alpha1.SchemeGroupVersion.WithKind(olmapiv1alpha1.ClusterServiceVersionKind),
+				namespace, packageServerName),
+			newObject(schema.GroupVersionKind{Group: "apiregistration.k8s.io", Version: "v1", Kind: "APIService"},
+				"", packagesAPIServiceName),
+		}},
+		{"deployments", append(filterResources(resources, func(r unstructured.Unstructured) bool {
+			return r.GetKind() == "Deployment"
+		}), newObject(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, namespace, packageServerName))},
+		{"resources", filterResources(resources, func(r unstructured.Unstructured) bool {
+			return r.GetKind() != "Deployment" && r.GetKind() != "Namespace" &&
+				r.GroupVersionKind().Group != olmapiv1alpha1.GroupName
+		})},
+		{"CRDs", crds},
+		{"namespaces", filterResources(resources, func(r unstructured.Unstructured) bool {
+			return r.GetKind() == "Namespace"
+		})},
+	}
+	for _, step := range steps {
+		log.Info(ctx, "Deleting OLM "+step.name, versionFields, phaseField("uninstall"))
+		if err := c.deleteAll(ctx, step.objects, opts.StripFinalizers); err != nil {
+			return fmt.Errorf("failed to delete the OLM %s: %w", step.name, err)
+		}
+	}
+	return nil
+}
+
+// installedResources returns the CRDs and the other resources of the
+// installation described by opts, to find the objects to delete or prune.
+// When the manifests of its release can't be read, e.g. for a release that
+// isn't bundled and can't be downloaded or verified, those of the nearest
+// bundled release are used instead: OLM releases share their object names,
+// and the objects are only looked up by name.
+func (c Client) installedResources(ctx context.Context, opts InstallOptions) ([]unstructured.Unstructured, []unstructured.Unstructured, error) {
+	crds, resources, err := c.getResources(ctx, opts)
+	if err == nil {
+		return crds, resources, nil
+	}
+	fallback := opts
+	fallback.Version = nearestBundledVersion(opts.Version)
+	fallback.Manifests, fallback.Checksums = ManifestSources{}, ManifestChecksums{}
+	if fallback.Version == "" || (SameVersion(fallback.Version, opts.Version) && opts.Manifests == ManifestSources{}) {
+		return nil, nil, err
+	}
+	c.Logger().Warn(ctx, "Failed to get the resources of the installed version, using those of a bundled version",
+		map[string]interface{}{"version": opts.Version, "bundled_version": fallback.Version, "error": err.Error()})
+	return c.getResources(ctx, fallback)
+}
+
+// nearestBundledVersion returns the newest bundled release that isn't newer
+// than version, or the oldest one when they all are.
+func nearestBundledVersion(version string) string {
+	target, err := semver.ParseTolerant(version)
+	var nearest, oldest *semver.Version
+	for _, v := range olmmanifests.Versions() {
+		sv, perr := semver.ParseTolerant(v)
+		if perr != nil {
+			continue
+		}
+		if oldest == nil || sv.LT(*oldest) {
+			oldest = &sv
+		}
+		if err == nil && sv.LTE(target) && (nearest == nil || sv.GT(*nearest)) {
+			nearest = &sv
+		}
+	}
+	if nearest == nil {
+		nearest = oldest
+	}
+	if nearest == nil {
+		return ""
+	}
+	return nearest.String()
+}
+
+// listCustomResources returns the objects of the kinds defined by crds in all
+// namespaces.
+func (c Client) listCustomResources(ctx context.Context, crds []unstructured.Unstructured) ([]unstructured.Unstructured, error) {
+	var objects []unstructured.Unstructured
+	for _, crd := range crds {
+		group, _, _ := unstructured.NestedString(crd.Object, "spec", "group")
+		kind, _, _ := unstructured.NestedString(crd.Object, "spec", "names", "kind")
+		versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+		var version string
+		for _, v := range versions {
+			if v, ok := v.(map[string]interface{}); ok && v["storage"] == true {
+				version, _ = v["name"].(string)
+			}
+		}
+		if group == "" || kind == "" || version == "" {
+			continue
+		}
+
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(schema.GroupVersionKind{Group: group, Version: version, Kind: kind + "List"})
+		if err := c.KubeClient.List(ctx, list); isGone(err) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %v", kind, err)
+		}
+		objects = append(objects, list.Items...)
+	}
+	return objects, nil
+}
+
+// deleteAll deletes objs and waits for them to be gone. Objects kept by
+// finalizers beyond finalizerGracePeriod fail with a FinalizerError, or have
+// their finalizers removed when strip is set.
+func (c Client) deleteAll(ctx context.Context, objs []unstructured.Unstructured, strip bool) error {
+	for i := range objs {
+		obj := &objs[i]
+		c.Logger().Info(ctx, "Deleting resource", olmresourceclient.ObjectFields(obj))
+		err := c.KubeClient.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !isGone(err) {
+			return fmt.Errorf("failed to delete %s %q: %v", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+
+	remaining, err := c.waitDeleted(ctx, objs, finalizerGracePeriod)
+	if err != nil || len(remaining) == 0 {
+		return err
+	}
+	stuck := stuckObjects(remaining)
+	if len(stuck) > 0 && !strip {
+		return &FinalizerError{Objects: stuck}
+	}
+	for i := range remaining {
+		if err := c.stripFinalizers(ctx, &remaining[i]); err != nil {
+			return err
+		}
+	}
+	_, err = c.waitDeleted(ctx, remaining, 0)
+	return err
+}
+
+// waitDeleted waits up to limit, or until ctx is done when limit is zero, for
+// objs to be gone. It returns the objects that are left.
+func (c Client) waitDeleted(ctx context.Context, objs []unstructured.Unstructured, limit time.Duration) ([]unstructured.Unstructured, error) {
+	waitCtx, cancel := ctx, context.CancelFunc(func() {})
+	if limit > 0 {
+		waitCtx, cancel = context.WithTimeout(ctx, limit)
+	}
+	defer cancel()
+
+	var remaining []unstructured.Unstructured
+	err := wait.PollUntilContextCancel(waitCtx, time.Second, true, func(pctx context.Context) (bool, error) {
+		remaining = nil
+		for _, obj := range objs {
+			live := &unstructured.Unstructured{}
+			live.SetGroupVersionKind(obj.GroupVersionKind())
+			if err := c.KubeClient.Get(pctx, client.ObjectKeyFromObject(&obj), live); isGone(err) {
+				continue
+			} else if err != nil {
+				return false, err
+			}
+			remaining = append(remaining, *live)
+		}
+		return len(remaining) == 0, nil
+	})
+	switch {
+	case err == nil:
+		return nil, nil
+	case ctx.Err() != nil:
+		names := make([]string, 0, len(remaining))
+		for _, obj := range remaining {
+			names = append(names, strings.ToLower(obj.GetKind())+"/"+obj.GetName())
+		}
+		return remaining, phaseError(ctx, PhaseDeletion,
+			fmt.Errorf("waiting for %s to be deleted: %v", strings.Join(names, ", "), err))
+	case waitCtx.Err() != nil:
+		return remaining, nil
+	default:
+		return remaining, err
+	}
+}
+
+// stuckObjects returns the objects among objs that are being deleted and wait
+// on finalizers.
+func stuckObjects(objs []unstructured.Unstructured) []StuckObject {
+	var stuck []StuckObject
+	for _, obj := range objs {
+		if obj.GetDeletionTimestamp() == nil {
+			continue
+		}
+		finalizers := obj.GetFinalizers()
+		// Namespaces are finalized by the namespace controller, which gets
+		// stuck when an aggregated API is unavailable.
+		if obj.GetKind() == "Namespace" {
+			specFinalizers, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "finalizers")
+			finalizers = append(finalizers, specFinalizers...)
+		}
+		if len(finalizers) > 0 {
+			stuck = append(stuck, StuckObject{
+				Kind:       obj.GetKind(),
+				Key:        types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()},
+				Finalizers: finalizers,
+			})
+		}
+	}
+	return stuck
+}
+
+// stripFinalizers removes the finalizers of obj, the spec finalizers of a
+// namespace through its finalize subresource.
+func (c Client) stripFinalizers(ctx context.Context, obj *unstructured.Unstructured) error {
+	if len(obj.GetFinalizers()) > 0 {
+		c.Logger().Warn(ctx, "Removing finalizers", olmresourceclient.ObjectFields(obj),
+			map[string]interface{}{"finalizers": obj.GetFinalizers()})
+		patch := client.RawPatch(types.MergePatchType, []byte(`{"metadata":{"finalizers":null}}`))
+		if err := c.KubeClient.Patch(ctx, obj, patch); err != nil && !isGone(err) {
+			return fmt.Errorf("failed to remove the finalizers of %s %q: %v", obj.GetKind(), obj.GetName(), err)
+		}
+	}
+	if obj.GetKind() == "Namespace" {
+		if finalizers, _, _ := unstructured.NestedStringSlice(obj.Object, "spec", "finalizers"); len(finalizers) > 0 {
+			c.Logger().Warn(ctx, "Finalizing namespace", olmresourceclient.ObjectFields(obj),
+				map[string]interface{}{"finalizers": finalizers})
+			unstructured.RemoveNestedField(obj.Object, "spec", "finalizers")
+			if err := c.KubeClient.SubResource("finalize").Update(ctx, obj); err != nil && !isGone(err) {
+				return fmt.Errorf("failed to finalize namespace %q: %v", obj.GetName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// newObject returns an object of kind gvk to look up or delete by name.
+func newObject(gvk schema.GroupVersionKind, namespace, name string) unstructured.Unstructured {
+	obj := unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gvk)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+// isGone reports whether err means the object, or its kind, doesn't exist.
+func isGone(err error) bool {
+	var noKind *meta.NoKindMatchError
+	return err != nil && (apierrors.IsNotFound(err) || meta.IsNoMatchError(err) || errors.As(err, &noKind))
+}
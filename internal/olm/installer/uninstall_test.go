@@ -0,0 +1,134 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+var _ = Describe("UninstallVersion", func() {
+	var gracePeriod time.Duration
+
+	BeforeEach(func() {
+		gracePeriod = finalizerGracePeriod
+		finalizerGracePeriod = 10 * time.Millisecond
+	})
+
+	AfterEach(func() {
+		finalizerGracePeriod = gracePeriod
+	})
+
+	subscription := func(finalizers ...string) *olmapiv1alpha1.Subscription {
+		return &olmapiv1alpha1.Subscription{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "operators", Name: "etcd", Finalizers: finalizers,
+		}}
+	}
+
+	It("deletes custom resources first and namespaces last", func() {
+		var deleted []string
+		kubeClient := fake.NewClientBuilder().WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "olm"}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "olm", Name: olmOperatorName}},
+			&olmapiv1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Namespace: "olm", Name: packageServerName}},
+			subscription(),
+		).WithInterceptorFuncs(interceptor.Funcs{
+			Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+				deleted = append(deleted, obj.GetObjectKind().GroupVersionKind().Kind+"/"+obj.GetName())
+				return c.Delete(ctx, obj, opts...)
+			},
+		}).Build()
+		c := Client{Client: &olmresourceclient.Client{KubeClient: kubeClient}}
+
+		Expect(c.UninstallVersion(context.TODO(), InstallOptions{Version: "0.26.0"})).To(Succeed())
+		Expect(deleted[0]).To(Equal("Subscription/etcd"))
+		Expect(deleted).To(ContainElements("ClusterServiceVersion/packageserver", "APIService/"+packagesAPIServiceName))
+		Expect(indexOf(deleted, "APIService/"+packagesAPIServiceName)).To(BeNumerically("<", indexOf(deleted, "Deployment/"+olmOperatorName)))
+		Expect(indexOf(deleted, "Deployment/"+olmOperatorName)).To(BeNumerically("<",
+			indexOf(deleted, "CustomResourceDefinition/subscriptions.operators.coreos.com")))
+		Expect(deleted[len(deleted)-2:]).To(ConsistOf("Namespace/olm", "Namespace/operators"))
+
+		Expect(kubeClient.Get(context.TODO(), client.ObjectKey{Name: "olm"}, &corev1.Namespace{})).NotTo(Succeed())
+	})
+
+	It("reports objects stuck on finalizers", func() {
+		c := Client{Client: &olmresourceclient.Client{KubeClient: fake.NewClientBuilder().WithObjects(
+			subscription("example.com/cleanup"),
+		).Build()}}
+		sub := toUnstructured(subscription())
+
+		err := c.deleteAll(context.TODO(), []unstructured.Unstructured{sub}, false)
+		var finalizerErr *FinalizerError
+		Expect(errors.As(err, &finalizerErr)).To(BeTrue(), "expected a finalizer error, got %v", err)
+		Expect(finalizerErr.Objects).To(HaveLen(1))
+		Expect(err).To(MatchError(ContainSubstring("subscription operators/etcd (finalizers: example.com/cleanup)")))
+	})
+
+	It("strips the finalizers of stuck objects when asked to", func() {
+		kubeClient := fake.NewClientBuilder().WithObjects(subscription("example.com/cleanup")).Build()
+		c := Client{Client: &olmresourceclient.Client{KubeClient: kubeClient}}
+
+		Expect(c.deleteAll(context.TODO(), []unstructured.Unstructured{toUnstructured(subscription())}, true)).To(Succeed())
+		err := kubeClient.Get(context.TODO(), client.ObjectKey{Namespace: "operators", Name: "etcd"}, &olmapiv1alpha1.Subscription{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("deletes the objects of a bundled release when the manifests can't be fetched", func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		defer server.Close()
+		kubeClient := fake.NewClientBuilder().WithObjects(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "olm"}},
+			&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: "olm", Name: olmOperatorName}},
+		).Build()
+		c := Client{
+			Client:          &olmresourceclient.Client{KubeClient: kubeClient},
+			HTTPClient:      *server.Client(),
+			BaseDownloadURL: server.URL,
+		}
+
+		Expect(c.UninstallVersion(context.TODO(), InstallOptions{Version: "0.27.0"})).To(Succeed())
+		err := kubeClient.Get(context.TODO(), client.ObjectKey{Namespace: "olm", Name: olmOperatorName}, &appsv1.Deployment{})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = DescribeTable("nearestBundledVersion",
+	func(version, expected string) {
+		Expect(nearestBundledVersion(version)).To(Equal(expected))
+	},
+	Entry("a bundled release", "v0.25.0", "0.25.0"),
+	Entry("a newer release", "0.27.1", "0.26.0"),
+	Entry("an older release", "0.20.0", "0.24.0"),
+	Entry("a constraint", "~> 0.26", "0.24.0"),
+)
+
+func toUnstructured(obj *olmapiv1alpha1.Subscription) unstructured.Unstructured {
+	u := unstructured.Unstructured{}
+	u.SetGroupVersionKind(olmapiv1alpha1.SchemeGroupVersion.WithKind(olmapiv1alpha1.SubscriptionKind))
+	u.SetNamespace(obj.Namespace)
+	u.SetName(obj.Name)
+	return u
+}
+
+func indexOf(items []string, item string) int {
+	for i, it := range items {
+		if it == item {
+			return i
+		}
+	}
+	return -1
+}
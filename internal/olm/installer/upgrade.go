@@ -0,0 +1,103 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+// UpgradeVersion moves the OLM installation described by from to the one
+// described by to in place. The manifests of the new version are applied
+// over the running installation with server-side apply, and the objects that
+// the new version no longer ships are pruned, so operators installed through
+// OLM keep running throughout. The objects of the current version are found
+// as on uninstall, see installedResources.
+func (c Client) UpgradeVersion(ctx context.Context, from, to InstallOptions) (*olmresourceclient.Status, error) {
+	crds, resources, err := c.getResources(ctx, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resources of version %q: %w", to.Version, err)
+	}
+
+	log := c.Logger()
+	namespace := to.olmNamespace()
+	versionFields := map[string]interface{}{"from": from.Version, "version": to.Version, "namespace": namespace}
+
+	// The current manifests are needed to find the objects to prune, an
+	// upgrade that can't prune would leave removed objects behind.
+	oldCrds, oldResources, err := c.installedResources(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get resources of the current version %q: %w", from.Version, err)
+	}
+
+	log.Info(ctx, "Applying OLM CRDs", versionFields, phaseField("crds"))
+	crdObjs := toObjects(crds...)
+	if err := c.DoApply(ctx, crdObjs...); err != nil {
+		return nil, fmt.Errorf("failed to apply CRDs: %v", err)
+	}
+
+	// Wait for CRDs to be established before applying other resources.
+	err = wait.PollUntilContextCancel(ctx, time.Second, false, func(ctx context.Context) (bool, error) {
+		status := c.GetObjectsStatus(ctx, crdObjs...)
+		return status.HasInstalledResources()
+	})
+	if err != nil {
+		return nil, phaseError(ctx, PhaseCRDs, fmt.Errorf("waiting for CRDs to be installed: %v", err))
+	}
+
+	log.Info(ctx, "Applying OLM resources", versionFields, phaseField("resources"))
+	if err := c.DoApply(ctx, toObjects(resources...)...); err != nil {
+		return nil, fmt.Errorf("failed to apply resources: %v", err)
+	}
+
+	// CRDs dropped by the new version are left in place: deleting them would
+	// delete every custom resource of their kind along with them.
+	for _, crd := range staleResources(oldCrds, crds) {
+		log.Warn(ctx, "CRD is no longer shipped by this OLM version and is left in place",
+			olmresourceclient.ObjectFields(&crd), versionFields)
+	}
+	if stale := staleResources(oldResources, resources); len(stale) > 0 {
+		log.Info(ctx, "Pruning resources removed from this OLM version", versionFields, phaseField("prune"))
+		if err := c.DoDelete(ctx, toObjects(stale...)...); err != nil {
+			return nil, fmt.Errorf("failed to prune resources: %v", err)
+		}
+	}
+
+	if err := c.waitForOLM(ctx, namespace, resources); err != nil {
+		return nil, err
+	}
+
+	objs := toObjects(append(crds, resources...)...)
+	status := c.GetObjectsStatus(ctx, objs...)
+	return &status, nil
+}
+
+// staleResources returns the resources in old that have no counterpart, by
+// kind, namespace and name, in current.
+func staleResources(old, current []unstructured.Unstructured) []unstructured.Unstructured {
+	type resourceKey struct {
+		group, kind string
+		types.NamespacedName
+	}
+	keyOf := func(r unstructured.Unstructured) resourceKey {
+		gvk := r.GroupVersionKind()
+		return resourceKey{gvk.Group, gvk.Kind, types.NamespacedName{Namespace: r.GetNamespace(), Name: r.GetName()}}
+	}
+
+	keep := make(map[resourceKey]struct{}, len(current))
+	for _, r := range current {
+		keep[keyOf(r)] = struct{}{}
+	}
+	var stale []unstructured.Unstructured
+	for _, r := range old {
+		if _, ok := keep[keyOf(r)]; !ok {
+			stale = append(stale, r)
+		}
+	}
+	return stale
+}
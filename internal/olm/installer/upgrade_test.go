@@ -0,0 +1,78 @@
+package installer
+
+import (
+	"context"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+var _ = Describe("staleResources", func() {
+	resource := func(apiVersion, kind, namespace, name string) unstructured.Unstructured {
+		u := unstructured.Unstructured{}
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		u.SetNamespace(namespace)
+		u.SetName(name)
+		return u
+	}
+
+	It("returns the resources missing from the new version", func() {
+		old := []unstructured.Unstructured{
+			resource("apps/v1", "Deployment", "olm", "olm-operator"),
+			resource("apps/v1", "Deployment", "olm", "legacy-operator"),
+			resource("v1", "ServiceAccount", "olm", "olm-operator-serviceaccount"),
+		}
+		current := []unstructured.Unstructured{
+			resource("apps/v1", "Deployment", "olm", "olm-operator"),
+			resource("v1", "ServiceAccount", "olm", "olm-operator-serviceaccount"),
+		}
+		stale := staleResources(old, current)
+		Expect(stale).To(HaveLen(1))
+		Expect(stale[0].GetName()).To(Equal("legacy-operator"))
+	})
+
+	It("tells resources apart by kind and namespace", func() {
+		old := []unstructured.Unstructured{
+			resource("v1", "ServiceAccount", "olm", "olm-operator"),
+			resource("apps/v1", "Deployment", "operators", "olm-operator"),
+		}
+		current := []unstructured.Unstructured{
+			resource("apps/v1", "Deployment", "olm", "olm-operator"),
+		}
+		Expect(staleResources(old, current)).To(HaveLen(2))
+	})
+
+	It("ignores API version changes of the same kind", func() {
+		old := []unstructured.Unstructured{
+			resource("apiextensions.k8s.io/v1beta1", "CustomResourceDefinition", "", "subscriptions.operators.coreos.com"),
+		}
+		current := []unstructured.Unstructured{
+			resource("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "subscriptions.operators.coreos.com"),
+		}
+		Expect(staleResources(old, current)).To(BeEmpty())
+	})
+
+	It("prunes nothing between identical versions", func() {
+		resources, err := getPackagedManifests(filepath.Join(bindataManifestPath, "0.26.0-olm.yaml"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(staleResources(resources, resources)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("installedResources", func() {
+	It("falls back to the nearest bundled manifests", func() {
+		c := Client{Client: &olmresourceclient.Client{}}
+		crds, resources, err := c.installedResources(context.TODO(), InstallOptions{
+			Version:   "0.26.0",
+			Manifests: ManifestSources{OLM: "does-not-exist.yaml"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(crds).NotTo(BeEmpty())
+		Expect(resources).To(ContainElement(WithTransform(resourceName, Equal("Deployment olm/"+olmOperatorName))))
+	})
+})
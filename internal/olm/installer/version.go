@@ -0,0 +1,97 @@
+// Copyright 2020 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package installer
+
+import (
+	"fmt"
+
+	"github.com/blang/semver/v4"
+	goversion "github.com/hashicorp/go-version"
+
+	olmmanifests "github.com/kaplan-michael/terraform-provider-olm/internal/bindata/olm"
+)
+
+// LatestVersion resolves to the newest OLM release.
+const LatestVersion = "latest"
+
+// CanonicalVersion returns version in the form OLM tracks its releases in,
+// semver without a "v" prefix, e.g. "0.26.0" for "v0.26.0". This is the form
+// of the bundled manifests and of the olm.version label on the packageserver
+// CSV. Versions that aren't semver, like "latest", are returned as-is.
+func CanonicalVersion(version string) string {
+	sv, err := semver.ParseTolerant(version)
+	if err != nil {
+		return version
+	}
+	return sv.String()
+}
+
+// SameVersion reports whether a and b name the same OLM release.
+func SameVersion(a, b string) bool {
+	return CanonicalVersion(a) == CanonicalVersion(b)
+}
+
+// IsBundledVersion reports whether the manifests of version are bundled with
+// the provider, and verified against embedded checksums.
+func IsBundledVersion(version string) bool {
+	return olmmanifests.HasVersion(CanonicalVersion(version))
+}
+
+// BundledVersions returns the versions bundled with the provider, sorted.
+func BundledVersions() []string {
+	return olmmanifests.Versions()
+}
+
+// IsVersionConstraint reports whether version is LatestVersion or a
+// constraint such as "~> 0.26" rather than a single release.
+func IsVersionConstraint(version string) bool {
+	_, err := semver.ParseTolerant(version)
+	return err != nil
+}
+
+// ValidateVersion returns an error when version is neither a release, a
+// constraint in the syntax of Terraform version constraints, nor LatestVersion.
+func ValidateVersion(version string) error {
+	if !IsVersionConstraint(version) {
+		return nil
+	}
+	if _, err := versionConstraints(version); err != nil {
+		return fmt.Errorf("%q is neither a version, a constraint such as \"~> 0.26\", nor %q", version, LatestVersion)
+	}
+	return nil
+}
+
+// VersionMatches reports whether the release version satisfies constraint,
+// which may also be a single release or LatestVersion.
+func VersionMatches(version, constraint string) bool {
+	if !IsVersionConstraint(constraint) {
+		return SameVersion(version, constraint)
+	}
+	constraints, err := versionConstraints(constraint)
+	if err != nil {
+		return false
+	}
+	v, err := goversion.NewVersion(version)
+	return err == nil && constraints.Check(v)
+}
+
+// versionConstraints parses constraint, LatestVersion matching every release
+// but pre-releases.
+func versionConstraints(constraint string) (goversion.Constraints, error) {
+	if constraint == LatestVersion {
+		constraint = ">= 0.0.0"
+	}
+	return goversion.NewConstraint(constraint)
+}
@@ -0,0 +1,94 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	olmmanifests "github.com/kaplan-michael/terraform-provider-olm/internal/bindata/olm"
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+var _ = Describe("versions", func() {
+	Describe("CanonicalVersion", func() {
+		It("drops the v prefix of semantic versions", func() {
+			Expect(CanonicalVersion("v0.26.0")).To(Equal("0.26.0"))
+			Expect(CanonicalVersion("0.26.0")).To(Equal("0.26.0"))
+			Expect(CanonicalVersion("v0.26")).To(Equal("0.26.0"))
+		})
+
+		It("returns a non semantic version as-is", func() {
+			Expect(CanonicalVersion("latest")).To(Equal("latest"))
+		})
+
+		It("compares versions regardless of their format", func() {
+			Expect(SameVersion("v0.26.0", "0.26.0")).To(BeTrue())
+			Expect(SameVersion("v0.25.0", "0.26.0")).To(BeFalse())
+		})
+	})
+
+	Describe("VersionMatches", func() {
+		It("matches releases, constraints and latest", func() {
+			Expect(VersionMatches("0.26.0", "v0.26.0")).To(BeTrue())
+			Expect(VersionMatches("0.27.0", "~> 0.26")).To(BeTrue())
+			Expect(VersionMatches("0.27.0", "~> 0.26.0")).To(BeFalse())
+			Expect(VersionMatches("0.27.0", LatestVersion)).To(BeTrue())
+			Expect(VersionMatches("0.28.0-rc.0", LatestVersion)).To(BeFalse())
+		})
+
+		It("validates constraints", func() {
+			Expect(ValidateVersion("0.26.0")).To(Succeed())
+			Expect(ValidateVersion("~> 0.26")).To(Succeed())
+			Expect(ValidateVersion(LatestVersion)).To(Succeed())
+			Expect(ValidateVersion("newest")).To(MatchError(ContainSubstring(`"newest" is neither a version`)))
+		})
+	})
+
+	Describe("fetchResources", func() {
+		var (
+			c        Client
+			requests []string
+		)
+
+		BeforeEach(func() {
+			requests = nil
+			c = Client{
+				Client: &olmresourceclient.Client{},
+				HTTPClient: http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					requests = append(requests, req.URL.String())
+					return nil, errors.New("network access is not allowed")
+				})},
+				BaseDownloadURL: DefaultBaseDownloadURL,
+			}
+		})
+
+		It("uses the bundled manifests regardless of a v prefix", func() {
+			Expect(olmmanifests.Versions()).NotTo(BeEmpty())
+			for _, version := range olmmanifests.Versions() {
+				for _, requested := range []string{version, "v" + version} {
+					By("requesting " + requested)
+					crds, resources, err := c.fetchResources(context.TODO(), requested)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(crds).NotTo(BeEmpty())
+					Expect(resources).NotTo(BeEmpty())
+				}
+			}
+			Expect(requests).To(BeEmpty())
+		})
+
+		It("downloads versions that aren't bundled", func() {
+			_, _, err := c.fetchResources(context.TODO(), "v0.20.0")
+			Expect(err).To(MatchError(ContainSubstring("network access is not allowed")))
+			Expect(requests).To(ConsistOf(DefaultBaseDownloadURL + "/download/v0.20.0/crds.yaml"))
+		})
+	})
+})
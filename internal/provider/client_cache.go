@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// clientCache hands out one installer.Client, and with it one dynamic
+// RESTMapper, per effective rest.Config and download options. Terraform calls
+// resources concurrently, so building clients is serialized to avoid duplicates.
+type clientCache struct {
+	mu      sync.Mutex
+	clients map[string]*installer.Client
+}
+
+func (c *clientCache) get(config *rest.Config, download installer.DownloadOptions) (*installer.Client, error) {
+	key, err := configKey(config, download)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cl, ok := c.clients[key]; ok {
+		return cl, nil
+	}
+	cl, err := installer.ClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	cl.Log = tflogLogger{}
+	if err := cl.SetDownloadOptions(download); err != nil {
+		return nil, err
+	}
+	if c.clients == nil {
+		c.clients = map[string]*installer.Client{}
+	}
+	c.clients[key] = cl
+	return cl, nil
+}
+
+// configKey returns a hash of the download options and the fields of config
+// that influence how the cluster is reached and authenticated against.
+func configKey(config *rest.Config, download installer.DownloadOptions) (string, error) {
+	key := struct {
+		Host            string
+		APIPath         string
+		TLSClientConfig rest.TLSClientConfig
+		BearerToken     string
+		BearerTokenFile string
+		Username        string
+		Password        string
+		Impersonate     rest.ImpersonationConfig
+		ExecProvider    *clientcmdapi.ExecConfig
+		AuthProvider    *clientcmdapi.AuthProviderConfig
+		Proxy           string
+		QPS             float32
+		Burst           int
+		Download        installer.DownloadOptions
+	}{
+		Host:            config.Host,
+		APIPath:         config.APIPath,
+		TLSClientConfig: config.TLSClientConfig,
+		BearerToken:     config.BearerToken,
+		BearerTokenFile: config.BearerTokenFile,
+		Username:        config.Username,
+		Password:        config.Password,
+		Impersonate:     config.Impersonate,
+		ExecProvider:    config.ExecProvider,
+		AuthProvider:    config.AuthProvider,
+		QPS:             config.QPS,
+		Burst:           config.Burst,
+		Download:        download,
+	}
+	// The proxy is a function, so key on the proxy it picks for the API server.
+	if config.Proxy != nil {
+		hostURL, err := url.Parse(config.Host)
+		if err != nil {
+			return "", fmt.Errorf("invalid host %q: %v", config.Host, err)
+		}
+		proxyURL, err := config.Proxy(&http.Request{URL: hostURL})
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve proxy: %v", err)
+		}
+		if proxyURL != nil {
+			key.Proxy = proxyURL.String()
+		}
+	}
+
+	data, err := json.Marshal(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash client config: %v", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestClientCache(t *testing.T) {
+	config := func() *rest.Config {
+		return &rest.Config{Host: "https://cluster.example.com", BearerToken: "token"}
+	}
+	download := installer.DownloadOptions{Retries: installer.DefaultDownloadRetries}
+	var cache clientCache
+
+	// Resources call get concurrently, they must all share one client.
+	clients := make([]*installer.Client, 8)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cl, err := cache.get(config(), download)
+			if err != nil {
+				t.Errorf("get: %v", err)
+			}
+			clients[i] = cl
+		}(i)
+	}
+	wg.Wait()
+	for _, cl := range clients {
+		if cl == nil || cl != clients[0] {
+			t.Fatalf("identical configs got different clients: %p and %p", cl, clients[0])
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		config   func(c *rest.Config)
+		download func(d *installer.DownloadOptions)
+	}{
+		{name: "host", config: func(c *rest.Config) { c.Host = "https://other.example.com" }},
+		{name: "credentials", config: func(c *rest.Config) { c.BearerToken = "rotated" }},
+		{name: "exec", config: func(c *rest.Config) {
+			c.ExecProvider = &clientcmdapi.ExecConfig{Command: "aws", APIVersion: "client.authentication.k8s.io/v1beta1"}
+		}},
+		{name: "download options", download: func(d *installer.DownloadOptions) { d.BaseURL = "https://mirror.example.com" }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c, d := config(), download
+			if tc.config != nil {
+				tc.config(c)
+			}
+			if tc.download != nil {
+				tc.download(&d)
+			}
+			cl, err := cache.get(c, d)
+			if err != nil {
+				t.Fatalf("get: %v", err)
+			}
+			if cl == clients[0] {
+				t.Errorf("a changed %s reused the client", tc.name)
+			}
+			if again, _ := cache.get(c, d); again != cl {
+				t.Errorf("the client for the changed %s wasn't reused", tc.name)
+			}
+		})
+	}
+}
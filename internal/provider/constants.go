@@ -1,3 +1,12 @@
 package provider
 
-const OLMv0Version = "v0.26.0"
+// OLMv0Version is the default OLM version, it must be one of the bundled
+// versions so the default install works without downloading manifests.
+const OLMv0Version = "0.26.0"
+
+// DefaultClientQPS and DefaultClientBurst raise the client-go rate limits (5 and 10)
+// so the rollout and CSV polling during installs is not throttled client side.
+const (
+	DefaultClientQPS   = 50
+	DefaultClientBurst = 100
+)
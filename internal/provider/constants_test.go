@@ -0,0 +1,16 @@
+package provider
+
+import (
+	"testing"
+
+	olmmanifests "github.com/kaplan-michael/terraform-provider-olm/internal/bindata/olm"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+)
+
+// The default install must not depend on downloading manifests.
+func TestDefaultVersionIsBundled(t *testing.T) {
+	if !olmmanifests.HasVersion(installer.CanonicalVersion(OLMv0Version)) {
+		t.Fatalf("default OLM version %q is not bundled, bundled versions are %v",
+			OLMv0Version, olmmanifests.Versions())
+	}
+}
@@ -0,0 +1,40 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+)
+
+// addInstallerError adds err to diags. Manifests that failed verification
+// are reported on the manifest_sha256 attribute, timeouts name the phase
+// that ran out of time on the timeouts block, and objects stuck on
+// finalizers are reported on strip_finalizers, and unavailable versions on
+// version.
+func addInstallerError(diags *diag.Diagnostics, summary string, err error) {
+	var checksumErr *installer.ChecksumError
+	var timeoutErr *installer.TimeoutError
+	var finalizerErr *installer.FinalizerError
+	var versionErr *installer.VersionError
+	switch {
+	case errors.As(err, &checksumErr):
+		diags.AddAttributeError(path.Root("manifest_sha256").AtMapKey(strings.TrimSuffix(checksumErr.File, ".yaml")),
+			summary, err.Error())
+	case errors.As(err, &timeoutErr):
+		diags.AddAttributeError(path.Root("timeouts"), summary,
+			fmt.Sprintf("Timed out in the %s phase, raise the timeout in the timeouts block if the cluster "+
+				"needs more time: %v", timeoutErr.Phase, err))
+	case errors.As(err, &finalizerErr):
+		diags.AddAttributeError(path.Root("strip_finalizers"), summary,
+			fmt.Sprintf("%v. Their controllers are gone or failing, remove the finalizers once it's safe to, "+
+				"or set strip_finalizers = true and apply it before destroying the instance.", err))
+	case errors.As(err, &versionErr):
+		diags.AddAttributeError(path.Root("version"), summary, err.Error())
+	default:
+		diags.AddError(summary, err.Error())
+	}
+}
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestAddInstallerError(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want path.Path
+	}{
+		{
+			name: "checksum",
+			err:  fmt.Errorf("failed to read the OLM manifests: %w", &installer.ChecksumError{File: "olm.yaml", Version: "0.27.0"}),
+			want: path.Root("manifest_sha256").AtMapKey("olm"),
+		},
+		{
+			name: "timeout",
+			err:  &installer.TimeoutError{Phase: installer.PhaseRollout, Err: errors.New("deployment/olm-operator failed to rollout")},
+			want: path.Root("timeouts"),
+		},
+		{
+			name: "finalizers",
+			err: fmt.Errorf("failed to delete the OLM namespaces: %w", &installer.FinalizerError{Objects: []installer.StuckObject{{
+				Kind: "Namespace", Key: types.NamespacedName{Name: "operators"}, Finalizers: []string{"kubernetes"},
+			}}}),
+			want: path.Root("strip_finalizers"),
+		},
+		{
+			name: "version",
+			err:  &installer.VersionError{Version: "0.99.0", Source: installer.DefaultBaseDownloadURL, Bundled: []string{"0.26.0"}},
+			want: path.Root("version"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			addInstallerError(&diags, "Failed to install OLM", tc.err)
+			if d, ok := diags[0].(diag.DiagnosticWithPath); !ok || !d.Path().Equal(tc.want) {
+				t.Errorf("diagnostic = %v, want an error of %s", diags[0], tc.want)
+			}
+		})
+	}
+
+	var diags diag.Diagnostics
+	addInstallerError(&diags, "Failed to install OLM", errors.New("connection refused"))
+	if _, ok := diags[0].(diag.DiagnosticWithPath); ok || diags.ErrorsCount() != 1 {
+		t.Errorf("diagnostics = %v, want a single error without attribute", diags)
+	}
+}
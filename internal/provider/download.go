@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+)
+
+// downloadOptions returns how the installer downloads the manifests of OLM
+// versions that aren't bundled with the provider.
+func (m *OLMProviderModel) downloadOptions() installer.DownloadOptions {
+	opts := installer.DownloadOptions{
+		BaseURL:     m.ManifestBaseURL.ValueString(),
+		Headers:     map[string]string{},
+		GitHubToken: m.GithubToken.ValueString(),
+		CAData:      []byte(m.ManifestCACertificate.ValueString()),
+		ProxyURL:    m.ManifestProxyURL.ValueString(),
+		Retries:     installer.DefaultDownloadRetries,
+		CacheDir:    installer.DefaultCacheDir(),
+	}
+	for name, value := range m.ManifestHTTPHeaders.Elements() {
+		opts.Headers[name] = value.(types.String).ValueString()
+	}
+	if !m.ManifestDownloadRetries.IsNull() {
+		opts.Retries = int(m.ManifestDownloadRetries.ValueInt64())
+	}
+	if !m.ManifestCacheDir.IsNull() {
+		opts.CacheDir = m.ManifestCacheDir.ValueString()
+	}
+	return opts
+}
+
+// validateDownload reports invalid manifest download settings.
+func (m *OLMProviderModel) validateDownload() diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	urlAttrs := []struct {
+		name  string
+		value string
+	}{
+		{"manifest_base_url", m.ManifestBaseURL.ValueString()},
+		{"manifest_proxy_url", m.ManifestProxyURL.ValueString()},
+	}
+	for _, attr := range urlAttrs {
+		if attr.value == "" {
+			continue
+		}
+		if u, err := url.Parse(attr.value); err != nil || u.Scheme == "" || u.Host == "" {
+			diags.AddAttributeError(path.Root(attr.name), "Invalid provider configuration",
+				fmt.Sprintf("%s must be an absolute URL, got %q.", attr.name, attr.value))
+		}
+	}
+	if !m.ManifestDownloadRetries.IsNull() && !m.ManifestDownloadRetries.IsUnknown() &&
+		m.ManifestDownloadRetries.ValueInt64() < 0 {
+		diags.AddAttributeError(path.Root("manifest_download_retries"), "Invalid provider configuration",
+			"manifest_download_retries can't be negative.")
+	}
+	return diags
+}
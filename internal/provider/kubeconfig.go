@@ -0,0 +1,418 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/homedir"
+)
+
+// OLMProviderExecModel describes the exec credential plugin configuration.
+type OLMProviderExecModel struct {
+	APIVersion types.String `tfsdk:"api_version"`
+	Command    types.String `tfsdk:"command"`
+	Args       types.List   `tfsdk:"args"`
+	Env        types.Map    `tfsdk:"env"`
+}
+
+// connectionMethod is a way of reaching the cluster, selected by setting
+// its attribute, with the environment variables of its settings.
+type connectionMethod struct {
+	selector types.String
+	env      map[string]*types.String
+}
+
+// connectionMethods returns the connection methods of the provider: a raw
+// kubeconfig, a kubeconfig path, or a host with its credentials.
+func (m *OLMProviderModel) connectionMethods() []connectionMethod {
+	kubeconfigEnv := func(env string, value *types.String) map[string]*types.String {
+		return map[string]*types.String{
+			env:                  value,
+			"KUBE_CTX":           &m.ConfigContext,
+			"KUBE_CTX_CLUSTER":   &m.ConfigContextCluster,
+			"KUBE_CTX_AUTH_INFO": &m.ConfigContextAuthInfo,
+		}
+	}
+	return []connectionMethod{
+		{m.Kubeconfig, kubeconfigEnv("OLM_KUBECONFIG", &m.Kubeconfig)},
+		{m.KubeconfigPath, kubeconfigEnv("KUBE_CONFIG_PATH", &m.KubeconfigPath)},
+		{m.Host, map[string]*types.String{
+			"KUBE_HOST":                 &m.Host,
+			"KUBE_CLUSTER_CA_CERT_DATA": &m.CACertificate,
+			"KUBE_CLIENT_CERT_DATA":     &m.ClientCertificate,
+			"KUBE_CLIENT_KEY_DATA":      &m.ClientKey,
+			"KUBE_TOKEN":                &m.Token,
+			"KUBE_USER":                 &m.Username,
+			"KUBE_PASSWORD":             &m.Password,
+		}},
+	}
+}
+
+// envFallbacks maps the provider attributes to the environment variables
+// used when the attribute is not set in the configuration. The variables of
+// the connection settings only complete the connection method selected in
+// the configuration, so that ambient variables such as KUBE_CONFIG_PATH on a
+// CI runner don't conflict with it, and all apply when none is selected.
+// Credentials don't apply on top of an exec plugin.
+func (m *OLMProviderModel) envFallbacks() map[string]*types.String {
+	fallbacks := map[string]*types.String{
+		"KUBE_IMPERSONATE_USER":     &m.ImpersonateUser,
+		"KUBE_TLS_SERVER_NAME":      &m.TLSServerName,
+		"KUBE_PROXY_URL":            &m.ProxyURL,
+		"OLM_MANIFEST_BASE_URL":     &m.ManifestBaseURL,
+		"OLM_GITHUB_TOKEN":          &m.GithubToken,
+		"OLM_MANIFEST_CA_CERT_DATA": &m.ManifestCACertificate,
+		"OLM_MANIFEST_PROXY_URL":    &m.ManifestProxyURL,
+		"OLM_MANIFEST_CACHE_DIR":    &m.ManifestCacheDir,
+	}
+
+	methods := m.connectionMethods()
+	selected := false
+	for _, method := range methods {
+		selected = selected || !method.selector.IsNull()
+	}
+	for _, method := range methods {
+		if selected && method.selector.IsNull() {
+			continue
+		}
+		for env, value := range method.env {
+			fallbacks[env] = value
+		}
+	}
+	if m.Exec != nil {
+		delete(fallbacks, "KUBE_TOKEN")
+		delete(fallbacks, "KUBE_USER")
+		delete(fallbacks, "KUBE_PASSWORD")
+	}
+	return fallbacks
+}
+
+// applyEnvDefaults fills every attribute that is not set in the configuration
+// from its environment variable, if present.
+func (m *OLMProviderModel) applyEnvDefaults() diag.Diagnostics {
+	var diags diag.Diagnostics
+	for env, v := range m.envFallbacks() {
+		if !v.IsNull() {
+			continue
+		}
+		if value, ok := os.LookupEnv(env); ok && value != "" {
+			*v = types.StringValue(value)
+		}
+	}
+
+	if value := os.Getenv("KUBE_IMPERSONATE_GROUPS"); m.ImpersonateGroups.IsNull() && value != "" {
+		var groups []attr.Value
+		for _, group := range strings.Split(value, ",") {
+			groups = append(groups, types.StringValue(strings.TrimSpace(group)))
+		}
+		m.ImpersonateGroups = types.ListValueMust(types.StringType, groups)
+	}
+	if value := os.Getenv("KUBE_INSECURE"); m.Insecure.IsNull() && value != "" {
+		insecure, err := strconv.ParseBool(value)
+		if err != nil {
+			diags.AddAttributeError(path.Root("insecure"), "Invalid environment variable",
+				fmt.Sprintf("KUBE_INSECURE must be a boolean: %v", err))
+		}
+		m.Insecure = types.BoolValue(insecure)
+	}
+	if value := os.Getenv("KUBE_QPS"); m.QPS.IsNull() && value != "" {
+		qps, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			diags.AddAttributeError(path.Root("qps"), "Invalid environment variable",
+				fmt.Sprintf("KUBE_QPS must be a number: %v", err))
+		}
+		m.QPS = types.Float64Value(qps)
+	}
+	if value := os.Getenv("KUBE_BURST"); m.Burst.IsNull() && value != "" {
+		burst, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			diags.AddAttributeError(path.Root("burst"), "Invalid environment variable",
+				fmt.Sprintf("KUBE_BURST must be an integer: %v", err))
+		}
+		m.Burst = types.Int64Value(burst)
+	}
+	if value := os.Getenv("OLM_MANIFEST_DOWNLOAD_RETRIES"); m.ManifestDownloadRetries.IsNull() && value != "" {
+		retries, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			diags.AddAttributeError(path.Root("manifest_download_retries"), "Invalid environment variable",
+				fmt.Sprintf("OLM_MANIFEST_DOWNLOAD_RETRIES must be an integer: %v", err))
+		}
+		m.ManifestDownloadRetries = types.Int64Value(retries)
+	}
+	return diags
+}
+
+// validate reports conflicting or incomplete connection settings. It expects
+// the environment defaults to be applied already, so the checks see the same
+// configuration restConfig will use. Unknown values count as set.
+func (m *OLMProviderModel) validate() diag.Diagnostics {
+	var diags diag.Diagnostics
+	isSet := func(v types.String) bool { return !v.IsNull() }
+
+	if isSet(m.Kubeconfig) && isSet(m.KubeconfigPath) {
+		diags.AddAttributeError(path.Root("kubeconfig_path"), "Conflicting provider configuration",
+			"Only one of kubeconfig and kubeconfig_path can be set, "+
+				"either in the configuration or through their environment variables.")
+	}
+	hasKubeconfig := isSet(m.Kubeconfig) || isSet(m.KubeconfigPath)
+
+	hostAttrs := []struct {
+		name  string
+		value types.String
+	}{
+		{"host", m.Host},
+		{"ca_certificate", m.CACertificate},
+		{"client_certificate", m.ClientCertificate},
+		{"client_key", m.ClientKey},
+	}
+	for _, attr := range hostAttrs {
+		if !isSet(attr.value) {
+			continue
+		}
+		if hasKubeconfig {
+			diags.AddAttributeError(path.Root(attr.name), "Conflicting provider configuration",
+				fmt.Sprintf("%s can't be combined with kubeconfig or kubeconfig_path, "+
+					"set the cluster connection in the kubeconfig instead.", attr.name))
+		} else if attr.name != "host" && !isSet(m.Host) {
+			diags.AddAttributeError(path.Root(attr.name), "Incomplete provider configuration",
+				fmt.Sprintf("%s requires host to be set.", attr.name))
+		}
+	}
+
+	contextAttrs := []struct {
+		name  string
+		value types.String
+	}{
+		{"config_context", m.ConfigContext},
+		{"config_context_cluster", m.ConfigContextCluster},
+		{"config_context_auth_info", m.ConfigContextAuthInfo},
+	}
+	for _, attr := range contextAttrs {
+		if isSet(attr.value) && !hasKubeconfig {
+			diags.AddAttributeError(path.Root(attr.name), "Incomplete provider configuration",
+				fmt.Sprintf("%s requires kubeconfig or kubeconfig_path to be set.", attr.name))
+		}
+	}
+
+	if isSet(m.ClientCertificate) != isSet(m.ClientKey) {
+		diags.AddAttributeError(path.Root("client_key"), "Incomplete provider configuration",
+			"client_certificate and client_key must be set together.")
+	}
+	if isSet(m.Username) != isSet(m.Password) {
+		diags.AddAttributeError(path.Root("password"), "Incomplete provider configuration",
+			"username and password must be set together.")
+	}
+	if isSet(m.Token) && isSet(m.Username) {
+		diags.AddAttributeError(path.Root("token"), "Conflicting provider configuration",
+			"token can't be combined with username and password.")
+	}
+
+	if !m.ImpersonateGroups.IsNull() && !isSet(m.ImpersonateUser) {
+		diags.AddAttributeError(path.Root("impersonate_groups"), "Incomplete provider configuration",
+			"impersonate_groups requires impersonate_user to be set.")
+	}
+	if m.Insecure.ValueBool() && isSet(m.CACertificate) {
+		diags.AddAttributeError(path.Root("insecure"), "Conflicting provider configuration",
+			"insecure can't be combined with ca_certificate.")
+	}
+	if v := m.ProxyURL.ValueString(); v != "" {
+		if _, err := url.Parse(v); err != nil {
+			diags.AddAttributeError(path.Root("proxy_url"), "Invalid provider configuration",
+				fmt.Sprintf("proxy_url is not a valid URL: %v", err))
+		}
+	}
+	if !m.QPS.IsNull() && !m.QPS.IsUnknown() && m.QPS.ValueFloat64() <= 0 {
+		diags.AddAttributeError(path.Root("qps"), "Invalid provider configuration",
+			"qps must be greater than zero.")
+	}
+	if !m.Burst.IsNull() && !m.Burst.IsUnknown() && m.Burst.ValueInt64() <= 0 {
+		diags.AddAttributeError(path.Root("burst"), "Invalid provider configuration",
+			"burst must be greater than zero.")
+	}
+
+	if m.Exec != nil {
+		if isSet(m.Token) || isSet(m.Username) {
+			diags.AddAttributeError(path.Root("exec"), "Conflicting provider configuration",
+				"The exec plugin can't be combined with token or username and password.")
+		}
+		if !isSet(m.Exec.Command) {
+			diags.AddAttributeError(path.Root("exec").AtName("command"), "Incomplete provider configuration",
+				"command is required in the exec block.")
+		}
+		if !isSet(m.Exec.APIVersion) {
+			diags.AddAttributeError(path.Root("exec").AtName("api_version"), "Incomplete provider configuration",
+				"api_version is required in the exec block.")
+		}
+	}
+	return diags
+}
+
+// restConfig builds the rest.Config for the cluster described by the provider
+// configuration. A raw kubeconfig takes precedence over kubeconfig_path, which
+// in turn takes precedence over the host and certificate attributes. When none
+// of them are set, the in-cluster service account config is used.
+// Explicitly configured credentials are applied on top of whichever source
+// was selected.
+func (m *OLMProviderModel) restConfig() (*rest.Config, error) {
+	config, err := m.baseRestConfig()
+	if err != nil {
+		return nil, err
+	}
+	m.applyCredentials(config)
+	if err := m.applyClientOptions(config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (m *OLMProviderModel) baseRestConfig() (*rest.Config, error) {
+	overrides := m.configOverrides()
+
+	switch {
+	case m.Kubeconfig.ValueString() != "":
+		raw, err := clientcmd.Load([]byte(m.Kubeconfig.ValueString()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse kubeconfig: %v", err)
+		}
+		return clientcmd.NewNonInteractiveClientConfig(*raw, overrides.CurrentContext, overrides, nil).ClientConfig()
+	case m.KubeconfigPath.ValueString() != "":
+		rules := &clientcmd.ClientConfigLoadingRules{
+			Precedence: expandKubeconfigPaths(m.KubeconfigPath.ValueString()),
+		}
+		return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides).ClientConfig()
+	case m.Host.ValueString() != "":
+		return &rest.Config{
+			Host: m.Host.ValueString(),
+			TLSClientConfig: rest.TLSClientConfig{
+				CertData: []byte(m.ClientCertificate.ValueString()),
+				KeyData:  []byte(m.ClientKey.ValueString()),
+				CAData:   []byte(m.CACertificate.ValueString()),
+			},
+		}, nil
+	}
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("insufficient configuration for OLM client initialization: "+
+			"one of kubeconfig, kubeconfig_path or host must be set when not running in a cluster: %v", err)
+	}
+	return config, nil
+}
+
+// applyCredentials overrides the authentication of config with the token,
+// basic auth or exec plugin set in the provider configuration.
+func (m *OLMProviderModel) applyCredentials(config *rest.Config) {
+	if m.Token.ValueString() != "" {
+		config.BearerToken = m.Token.ValueString()
+		config.BearerTokenFile = ""
+	}
+	if m.Username.ValueString() != "" {
+		config.Username = m.Username.ValueString()
+		config.Password = m.Password.ValueString()
+	}
+	if m.Exec != nil {
+		config.ExecProvider = m.Exec.execConfig()
+		config.AuthProvider = nil
+	}
+}
+
+// applyClientOptions sets impersonation, TLS, proxy and rate limit options on config.
+func (m *OLMProviderModel) applyClientOptions(config *rest.Config) error {
+	if m.ImpersonateUser.ValueString() != "" {
+		config.Impersonate.UserName = m.ImpersonateUser.ValueString()
+		for _, group := range m.ImpersonateGroups.Elements() {
+			if g, ok := group.(types.String); ok {
+				config.Impersonate.Groups = append(config.Impersonate.Groups, g.ValueString())
+			}
+		}
+	}
+	if m.Insecure.ValueBool() {
+		// client-go refuses insecure connections when a CA is configured.
+		config.TLSClientConfig.Insecure = true
+		config.TLSClientConfig.CAData = nil
+		config.TLSClientConfig.CAFile = ""
+	}
+	if m.TLSServerName.ValueString() != "" {
+		config.TLSClientConfig.ServerName = m.TLSServerName.ValueString()
+	}
+	if m.ProxyURL.ValueString() != "" {
+		proxyURL, err := url.Parse(m.ProxyURL.ValueString())
+		if err != nil {
+			return fmt.Errorf("invalid proxy_url: %v", err)
+		}
+		config.Proxy = http.ProxyURL(proxyURL)
+	}
+
+	config.QPS = DefaultClientQPS
+	if !m.QPS.IsNull() {
+		config.QPS = float32(m.QPS.ValueFloat64())
+	}
+	config.Burst = DefaultClientBurst
+	if !m.Burst.IsNull() {
+		config.Burst = int(m.Burst.ValueInt64())
+	}
+	return nil
+}
+
+// execConfig converts the exec block into a client-go exec credential plugin config.
+func (e *OLMProviderExecModel) execConfig() *clientcmdapi.ExecConfig {
+	exec := &clientcmdapi.ExecConfig{
+		APIVersion:      e.APIVersion.ValueString(),
+		Command:         e.Command.ValueString(),
+		InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+	}
+	for _, arg := range e.Args.Elements() {
+		exec.Args = append(exec.Args, arg.(types.String).ValueString())
+	}
+	env := e.Env.Elements()
+	names := make([]string, 0, len(env))
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		exec.Env = append(exec.Env, clientcmdapi.ExecEnvVar{Name: name, Value: env[name].(types.String).ValueString()})
+	}
+	return exec
+}
+
+// configOverrides returns the context selection requested in the provider configuration.
+func (m *OLMProviderModel) configOverrides() *clientcmd.ConfigOverrides {
+	overrides := &clientcmd.ConfigOverrides{}
+	overrides.CurrentContext = m.ConfigContext.ValueString()
+	overrides.Context.Cluster = m.ConfigContextCluster.ValueString()
+	overrides.Context.AuthInfo = m.ConfigContextAuthInfo.ValueString()
+	return overrides
+}
+
+// expandKubeconfigPaths splits a KUBECONFIG style path list and expands a
+// leading "~" in each entry to the user's home directory.
+func expandKubeconfigPaths(pathList string) []string {
+	var paths []string
+	for _, p := range filepath.SplitList(pathList) {
+		if p == "" {
+			continue
+		}
+		if p == "~" || strings.HasPrefix(p, "~/") {
+			p = filepath.Join(homedir.HomeDir(), strings.TrimPrefix(p, "~"))
+		}
+		paths = append(paths, p)
+	}
+	return paths
+}
@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestApplyEnvDefaults(t *testing.T) {
+	t.Setenv("KUBE_CONFIG_PATH", "/ci/kubeconfig")
+	t.Setenv("KUBE_CTX", "ci")
+	t.Setenv("KUBE_HOST", "https://ci.example.com")
+	t.Setenv("KUBE_TOKEN", "ci-token")
+
+	for _, tc := range []struct {
+		name      string
+		config    OLMProviderModel
+		want      func(m OLMProviderModel) bool
+		conflicts bool
+	}{
+		{
+			name:   "no connection method",
+			config: OLMProviderModel{},
+			want: func(m OLMProviderModel) bool {
+				return m.KubeconfigPath.ValueString() == "/ci/kubeconfig" && m.Host.ValueString() == "https://ci.example.com"
+			},
+			conflicts: true,
+		},
+		{
+			name:   "host",
+			config: OLMProviderModel{Host: types.StringValue("https://prod.example.com")},
+			want: func(m OLMProviderModel) bool {
+				return m.KubeconfigPath.IsNull() && m.ConfigContext.IsNull() && m.Token.ValueString() == "ci-token"
+			},
+		},
+		{
+			name:   "kubeconfig",
+			config: OLMProviderModel{Kubeconfig: types.StringValue("apiVersion: v1")},
+			want: func(m OLMProviderModel) bool {
+				return m.KubeconfigPath.IsNull() && m.Host.IsNull() && m.Token.IsNull() && m.ConfigContext.ValueString() == "ci"
+			},
+		},
+		{
+			name: "exec",
+			config: OLMProviderModel{
+				Host: types.StringValue("https://prod.example.com"),
+				Exec: &OLMProviderExecModel{Command: types.StringValue("aws"), APIVersion: types.StringValue("v1")},
+			},
+			want: func(m OLMProviderModel) bool {
+				return m.Token.IsNull()
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := tc.config
+			if diags := m.applyEnvDefaults(); diags.HasError() {
+				t.Fatalf("unexpected diagnostics: %v", diags)
+			}
+			if !tc.want(m) {
+				t.Errorf("unexpected configuration %+v", m)
+			}
+			if diags := m.validate(); diags.HasError() != tc.conflicts {
+				t.Errorf("validation errors: %v, want conflicts: %t", diags, tc.conflicts)
+			}
+		})
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+)
+
+// tflogLogger sends the progress of OLM operations to Terraform's logs,
+// so it shows up with TF_LOG_PROVIDER.
+type tflogLogger struct{}
+
+var _ olmresourceclient.Logger = tflogLogger{}
+
+func (tflogLogger) Debug(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	tflog.Debug(ctx, msg, fields...)
+}
+
+func (tflogLogger) Info(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	tflog.Info(ctx, msg, fields...)
+}
+
+func (tflogLogger) Warn(ctx context.Context, msg string, fields ...map[string]interface{}) {
+	tflog.Warn(ctx, msg, fields...)
+}
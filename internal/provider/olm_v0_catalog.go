@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+)
+
+// OLMCatalogModel customizes or disables the default CatalogSource.
+type OLMCatalogModel struct {
+	Enabled                types.Bool   `tfsdk:"enabled"`
+	Image                  types.String `tfsdk:"image"`
+	UpdateStrategyInterval types.String `tfsdk:"update_strategy_interval"`
+	DisplayName            types.String `tfsdk:"display_name"`
+	Publisher              types.String `tfsdk:"publisher"`
+}
+
+// options returns the installer catalog options described by m.
+func (m *OLMCatalogModel) options() installer.CatalogOptions {
+	if m == nil {
+		return installer.CatalogOptions{}
+	}
+	return installer.CatalogOptions{
+		Disabled:       !m.Enabled.IsNull() && !m.Enabled.ValueBool(),
+		Image:          m.Image.ValueString(),
+		UpdateInterval: m.UpdateStrategyInterval.ValueString(),
+		DisplayName:    m.DisplayName.ValueString(),
+		Publisher:      m.Publisher.ValueString(),
+	}
+}
+
+// validate reports an update interval OLM can't parse.
+func (m *OLMCatalogModel) validate(block path.Path) (diags diag.Diagnostics) {
+	if m == nil || m.UpdateStrategyInterval.IsNull() || m.UpdateStrategyInterval.IsUnknown() {
+		return nil
+	}
+	if _, err := time.ParseDuration(m.UpdateStrategyInterval.ValueString()); err != nil {
+		diags.AddAttributeError(block.AtName("update_strategy_interval"), "Invalid update strategy interval",
+			fmt.Sprintf("update_strategy_interval must be a duration such as 60m: %v", err))
+	}
+	return diags
+}
+
+// catalogBlock returns the schema of the default_catalog block of olm_v0_instance.
+func catalogBlock() schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "The default `" + installer.DefaultCatalogName + "` CatalogSource, " +
+			"the operatorhub.io community catalog. Changing it updates OLM in place",
+		Attributes: map[string]schema.Attribute{
+			"enabled": schema.BoolAttribute{
+				MarkdownDescription: "Whether the catalog is installed, defaults to true. " +
+					"Disable it when operators may only come from your own catalogs",
+				Optional: true,
+			},
+			"image": schema.StringAttribute{
+				MarkdownDescription: "Catalog image replacing `quay.io/operatorhubio/catalog:latest`, " +
+					"e.g. a mirror of the catalog",
+				Optional: true,
+			},
+			"update_strategy_interval": schema.StringAttribute{
+				MarkdownDescription: "How often the catalog image is polled for updates, e.g. `60m`",
+				Optional:            true,
+			},
+			"display_name": schema.StringAttribute{
+				MarkdownDescription: "Display name of the catalog",
+				Optional:            true,
+			},
+			"publisher": schema.StringAttribute{
+				MarkdownDescription: "Publisher of the catalog",
+				Optional:            true,
+			},
+		},
+	}
+}
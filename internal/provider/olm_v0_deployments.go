@@ -0,0 +1,243 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// OLMDeploymentModel tunes the deployment of an OLM component.
+type OLMDeploymentModel struct {
+	Replicas          types.Int64          `tfsdk:"replicas"`
+	PriorityClassName types.String         `tfsdk:"priority_class_name"`
+	NodeSelector      types.Map            `tfsdk:"node_selector"`
+	Affinity          types.String         `tfsdk:"affinity"`
+	ExtraArgs         types.List           `tfsdk:"extra_args"`
+	Resources         *OLMResourcesModel   `tfsdk:"resources"`
+	Tolerations       []OLMTolerationModel `tfsdk:"toleration"`
+}
+
+// OLMResourcesModel is the compute resources of a container.
+type OLMResourcesModel struct {
+	Requests types.Map `tfsdk:"requests"`
+	Limits   types.Map `tfsdk:"limits"`
+}
+
+// OLMTolerationModel is a toleration of the pods of a deployment.
+type OLMTolerationModel struct {
+	Key               types.String `tfsdk:"key"`
+	Operator          types.String `tfsdk:"operator"`
+	Value             types.String `tfsdk:"value"`
+	Effect            types.String `tfsdk:"effect"`
+	TolerationSeconds types.Int64  `tfsdk:"toleration_seconds"`
+}
+
+// options returns the installer deployment options described by m. The
+// values are expected to have passed validate.
+func (m *OLMDeploymentModel) options() installer.DeploymentOptions {
+	if m == nil {
+		return installer.DeploymentOptions{}
+	}
+	var opts installer.DeploymentOptions
+	if !m.Replicas.IsNull() {
+		replicas := int32(m.Replicas.ValueInt64())
+		opts.Replicas = &replicas
+	}
+	opts.PriorityClassName = m.PriorityClassName.ValueString()
+	if !m.NodeSelector.IsNull() {
+		opts.NodeSelector = make(map[string]string, len(m.NodeSelector.Elements()))
+		for key, value := range m.NodeSelector.Elements() {
+			opts.NodeSelector[key] = value.(types.String).ValueString()
+		}
+	}
+	if affinity := m.Affinity.ValueString(); affinity != "" {
+		opts.Affinity, _ = parseAffinity(affinity)
+	}
+	for _, arg := range m.ExtraArgs.Elements() {
+		opts.ExtraArgs = append(opts.ExtraArgs, arg.(types.String).ValueString())
+	}
+	if m.Resources != nil {
+		opts.Resources = &corev1.ResourceRequirements{
+			Requests: resourceList(m.Resources.Requests),
+			Limits:   resourceList(m.Resources.Limits),
+		}
+	}
+	for _, t := range m.Tolerations {
+		toleration := corev1.Toleration{
+			Key:      t.Key.ValueString(),
+			Operator: corev1.TolerationOperator(t.Operator.ValueString()),
+			Value:    t.Value.ValueString(),
+			Effect:   corev1.TaintEffect(t.Effect.ValueString()),
+		}
+		if !t.TolerationSeconds.IsNull() {
+			seconds := t.TolerationSeconds.ValueInt64()
+			toleration.TolerationSeconds = &seconds
+		}
+		opts.Tolerations = append(opts.Tolerations, toleration)
+	}
+	return opts
+}
+
+// validate reports the values of m the Kubernetes API would reject.
+func (m *OLMDeploymentModel) validate(block path.Path) (diags diag.Diagnostics) {
+	if m == nil {
+		return nil
+	}
+	if !m.Replicas.IsNull() && !m.Replicas.IsUnknown() && m.Replicas.ValueInt64() < 0 {
+		diags.AddAttributeError(block.AtName("replicas"), "Invalid replicas", "replicas must not be negative")
+	}
+	if !m.Affinity.IsNull() && !m.Affinity.IsUnknown() {
+		if _, err := parseAffinity(m.Affinity.ValueString()); err != nil {
+			diags.AddAttributeError(block.AtName("affinity"), "Invalid affinity",
+				fmt.Sprintf("affinity must be a Kubernetes Affinity in JSON: %v", err))
+		}
+	}
+	if m.Resources != nil {
+		for _, field := range []struct {
+			name       string
+			quantities types.Map
+		}{
+			{"requests", m.Resources.Requests},
+			{"limits", m.Resources.Limits},
+		} {
+			// The quantities are checked once they are known
+			if field.quantities.IsUnknown() {
+				continue
+			}
+			elements := field.quantities.Elements()
+			names := make([]string, 0, len(elements))
+			for name := range elements {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				quantity := elements[name].(types.String)
+				if quantity.IsUnknown() {
+					continue
+				}
+				if _, err := resource.ParseQuantity(quantity.ValueString()); err != nil {
+					diags.AddAttributeError(block.AtName("resources").AtName(field.name).AtMapKey(name),
+						"Invalid quantity", fmt.Sprintf("%q is not a valid quantity: %v", quantity.ValueString(), err))
+				}
+			}
+		}
+	}
+	for i, t := range m.Tolerations {
+		if operator := t.Operator.ValueString(); !t.Operator.IsUnknown() && operator != "" &&
+			operator != string(corev1.TolerationOpExists) && operator != string(corev1.TolerationOpEqual) {
+			diags.AddAttributeError(block.AtName("toleration").AtListIndex(i).AtName("operator"),
+				"Invalid toleration operator", "operator must be Exists or Equal")
+		}
+	}
+	return diags
+}
+
+// parseAffinity strictly decodes a JSON encoded Affinity.
+func parseAffinity(value string) (*corev1.Affinity, error) {
+	dec := json.NewDecoder(bytes.NewBufferString(value))
+	dec.DisallowUnknownFields()
+	affinity := &corev1.Affinity{}
+	if err := dec.Decode(affinity); err != nil {
+		return nil, err
+	}
+	return affinity, nil
+}
+
+func resourceList(quantities types.Map) corev1.ResourceList {
+	if quantities.IsNull() {
+		return nil
+	}
+	list := make(corev1.ResourceList, len(quantities.Elements()))
+	for name, quantity := range quantities.Elements() {
+		if q, err := resource.ParseQuantity(quantity.(types.String).ValueString()); err == nil {
+			list[corev1.ResourceName(name)] = q
+		}
+	}
+	return list
+}
+
+// deploymentBlock returns the schema of the block tuning the deployment of component.
+func deploymentBlock(component string) schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Tuning of the " + component + " deployment. Changing it updates OLM in place",
+		Attributes: map[string]schema.Attribute{
+			"replicas": schema.Int64Attribute{
+				MarkdownDescription: "Number of replicas",
+				Optional:            true,
+			},
+			"priority_class_name": schema.StringAttribute{
+				MarkdownDescription: "Priority class of the pods",
+				Optional:            true,
+			},
+			"node_selector": schema.MapAttribute{
+				MarkdownDescription: "Node labels the pods are scheduled on, merged into the upstream " +
+					"`kubernetes.io/os: linux` selector",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"affinity": schema.StringAttribute{
+				MarkdownDescription: "Affinity of the pods in the Kubernetes API format, encoded with " +
+					"`jsonencode`, e.g. `jsonencode({ podAntiAffinity = { ... } })`",
+				Optional: true,
+			},
+			"extra_args": schema.ListAttribute{
+				MarkdownDescription: "Arguments appended to the ones of the container",
+				ElementType:         types.StringType,
+				Optional:            true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"resources": schema.SingleNestedBlock{
+				MarkdownDescription: "Compute resources of the container, replacing the upstream requests",
+				Attributes: map[string]schema.Attribute{
+					"requests": schema.MapAttribute{
+						MarkdownDescription: "Requested resources, e.g. `{ cpu = \"10m\", memory = \"160Mi\" }`",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+					"limits": schema.MapAttribute{
+						MarkdownDescription: "Resource limits",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+				},
+			},
+			"toleration": schema.ListNestedBlock{
+				MarkdownDescription: "Tolerations of the pods",
+				NestedObject: schema.NestedBlockObject{
+					Attributes: map[string]schema.Attribute{
+						"key": schema.StringAttribute{
+							MarkdownDescription: "Taint key the toleration applies to, empty matches all keys",
+							Optional:            true,
+						},
+						"operator": schema.StringAttribute{
+							MarkdownDescription: "`Exists` or `Equal`, defaults to `Equal`",
+							Optional:            true,
+						},
+						"value": schema.StringAttribute{
+							MarkdownDescription: "Taint value the toleration matches with the `Equal` operator",
+							Optional:            true,
+						},
+						"effect": schema.StringAttribute{
+							MarkdownDescription: "Taint effect to match, empty matches all effects",
+							Optional:            true,
+						},
+						"toleration_seconds": schema.Int64Attribute{
+							MarkdownDescription: "How long a `NoExecute` taint is tolerated",
+							Optional:            true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
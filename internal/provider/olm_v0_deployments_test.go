@@ -0,0 +1,67 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestDeploymentOptions(t *testing.T) {
+	m := &OLMDeploymentModel{
+		Replicas: types.Int64Value(3),
+		NodeSelector: types.MapValueMust(types.StringType, map[string]attr.Value{
+			"node-role.kubernetes.io/infra": types.StringValue(""),
+		}),
+		Affinity:  types.StringValue(`{"podAntiAffinity":{"preferredDuringSchedulingIgnoredDuringExecution":[{"weight":100,"podAffinityTerm":{"topologyKey":"kubernetes.io/hostname"}}]}}`),
+		ExtraArgs: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("--debug")}),
+		Resources: &OLMResourcesModel{
+			Requests: types.MapValueMust(types.StringType, map[string]attr.Value{"memory": types.StringValue("256Mi")}),
+		},
+		Tolerations: []OLMTolerationModel{{
+			Key:      types.StringValue("node-role.kubernetes.io/infra"),
+			Operator: types.StringValue("Exists"),
+		}},
+	}
+	if diags := m.validate(path.Root("packageserver")); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	opts := m.options()
+	if opts.Replicas == nil || *opts.Replicas != 3 {
+		t.Errorf("replicas = %v, want 3", opts.Replicas)
+	}
+	if opts.NodeSelector["node-role.kubernetes.io/infra"] != "" || len(opts.NodeSelector) != 1 {
+		t.Errorf("node selector = %v, want the infra role", opts.NodeSelector)
+	}
+	if len(opts.ExtraArgs) != 1 || opts.ExtraArgs[0] != "--debug" {
+		t.Errorf("extra args = %v, want --debug", opts.ExtraArgs)
+	}
+	if opts.Affinity == nil || opts.Affinity.PodAntiAffinity == nil {
+		t.Errorf("affinity = %v, want a pod anti-affinity", opts.Affinity)
+	}
+	if memory := opts.Resources.Requests[corev1.ResourceMemory]; memory.String() != "256Mi" {
+		t.Errorf("memory request = %s, want 256Mi", memory.String())
+	}
+	if len(opts.Tolerations) != 1 || opts.Tolerations[0].Operator != corev1.TolerationOpExists {
+		t.Errorf("tolerations = %v, want a single Exists toleration", opts.Tolerations)
+	}
+}
+
+func TestDeploymentOptionsValidate(t *testing.T) {
+	m := &OLMDeploymentModel{
+		Replicas: types.Int64Value(-1),
+		Affinity: types.StringValue(`{"nodeAfinity":{}}`),
+		Resources: &OLMResourcesModel{
+			Requests: types.MapUnknown(types.StringType),
+			Limits:   types.MapValueMust(types.StringType, map[string]attr.Value{"cpu": types.StringValue("a lot")}),
+		},
+		Tolerations: []OLMTolerationModel{{Operator: types.StringValue("In")}},
+	}
+	diags := m.validate(path.Root("olm_operator"))
+	if diags.ErrorsCount() != 4 {
+		t.Fatalf("got %d errors, want 4: %v", diags.ErrorsCount(), diags)
+	}
+}
@@ -0,0 +1,85 @@
+package provider
+
+import (
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+)
+
+// OLMImagesModel overrides the images of the OLM components.
+type OLMImagesModel struct {
+	Registry        types.String   `tfsdk:"registry"`
+	OLMOperator     *OLMImageModel `tfsdk:"olm_operator"`
+	CatalogOperator *OLMImageModel `tfsdk:"catalog_operator"`
+	PackageServer   *OLMImageModel `tfsdk:"packageserver"`
+	Util            *OLMImageModel `tfsdk:"util"`
+	ConfigmapServer *OLMImageModel `tfsdk:"configmap_server"`
+}
+
+// OLMImageModel overrides the image of a single OLM component.
+type OLMImageModel struct {
+	Image  types.String `tfsdk:"image"`
+	Digest types.String `tfsdk:"digest"`
+}
+
+// overrides returns the installer image overrides described by m.
+func (m *OLMImagesModel) overrides() installer.ImageOverrides {
+	if m == nil {
+		return installer.ImageOverrides{}
+	}
+	return installer.ImageOverrides{
+		Registry:        m.Registry.ValueString(),
+		OLMOperator:     m.OLMOperator.override(),
+		CatalogOperator: m.CatalogOperator.override(),
+		PackageServer:   m.PackageServer.override(),
+		Util:            m.Util.override(),
+		ConfigmapServer: m.ConfigmapServer.override(),
+	}
+}
+
+func (m *OLMImageModel) override() installer.ImageOverride {
+	if m == nil {
+		return installer.ImageOverride{}
+	}
+	return installer.ImageOverride{Image: m.Image.ValueString(), Digest: m.Digest.ValueString()}
+}
+
+// imagesBlock returns the schema of the images block of olm_v0_instance.
+func imagesBlock() schema.SingleNestedBlock {
+	imageBlock := func(component string) schema.SingleNestedBlock {
+		return schema.SingleNestedBlock{
+			MarkdownDescription: "Image of " + component,
+			Attributes: map[string]schema.Attribute{
+				"image": schema.StringAttribute{
+					MarkdownDescription: "Image replacing the upstream one, with a tag or digest. " +
+						"Takes precedence over `registry`",
+					Optional: true,
+				},
+				"digest": schema.StringAttribute{
+					MarkdownDescription: "Digest the image is pinned to, e.g. `sha256:...`, replacing its tag or digest",
+					Optional:            true,
+				},
+			},
+		}
+	}
+
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Overrides of the OLM component images, e.g. to pull them from a registry mirror " +
+			"in air-gapped clusters. Changing them updates OLM in place",
+		Attributes: map[string]schema.Attribute{
+			"registry": schema.StringAttribute{
+				MarkdownDescription: "Registry replacing the one of every upstream OLM image, " +
+					"e.g. `mirror.example.com/quay.io` pulls `quay.io/operator-framework/olm` from " +
+					"`mirror.example.com/quay.io/operator-framework/olm`",
+				Optional: true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"olm_operator":     imageBlock("the olm-operator deployment"),
+			"catalog_operator": imageBlock("the catalog-operator deployment"),
+			"packageserver":    imageBlock("the packageserver deployment"),
+			"util":             imageBlock("the bundle unpacking jobs, the `--util-image` flag of catalog-operator"),
+			"configmap_server": imageBlock("ConfigMap catalogs, the `--configmapServerImage` flag of catalog-operator"),
+		},
+	}
+}
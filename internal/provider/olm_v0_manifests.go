@@ -0,0 +1,92 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+)
+
+// OLMManifestsModel replaces the upstream manifests of the OLM release.
+type OLMManifestsModel struct {
+	CRDs types.String `tfsdk:"crds"`
+	OLM  types.String `tfsdk:"olm"`
+}
+
+// sources returns the installer manifest sources described by m.
+func (m *OLMManifestsModel) sources() installer.ManifestSources {
+	if m == nil {
+		return installer.ManifestSources{}
+	}
+	return installer.ManifestSources{CRDs: m.CRDs.ValueString(), OLM: m.OLM.ValueString()}
+}
+
+// manifestsBlock returns the schema of the manifests block of olm_v0_instance.
+func manifestsBlock() schema.SingleNestedBlock {
+	source := "An `https://` URL, the path of a local file, or the manifests themselves, " +
+		"e.g. read with `file()` or `templatefile()`"
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Manifests replacing the upstream ones of `version`, e.g. a patched or release " +
+			"candidate build of OLM, or vetted manifests checked into your repository. `version` must still " +
+			"name the OLM release the manifests are from. Changing them updates OLM in place. Changes to the " +
+			"content behind a path or URL alone are not detected, read the file with `file()` to track them",
+		Attributes: map[string]schema.Attribute{
+			"crds": schema.StringAttribute{
+				MarkdownDescription: "Replaces `crds.yaml`. " + source,
+				Optional:            true,
+			},
+			"olm": schema.StringAttribute{
+				MarkdownDescription: "Replaces `olm.yaml`. " + source,
+				Optional:            true,
+			},
+		},
+	}
+}
+
+// sha256Pattern matches a hex encoded sha256 checksum, optionally prefixed with "sha256:".
+var sha256Pattern = regexp.MustCompile(`^(sha256:)?[0-9a-fA-F]{64}$`)
+
+// manifestChecksums returns the installer manifest checksums of the
+// manifest_sha256 attribute, keyed by manifest file without extension.
+func manifestChecksums(checksums types.Map) installer.ManifestChecksums {
+	elements := checksums.Elements()
+	crds, _ := elements["crds"].(types.String)
+	olm, _ := elements["olm"].(types.String)
+	return installer.ManifestChecksums{
+		CRDs: crds.ValueString(),
+		OLM:  olm.ValueString(),
+	}
+}
+
+// validateManifestChecksums reports the keys and checksums of the
+// manifest_sha256 attribute the installer doesn't understand. They're
+// checked once the attribute is known.
+func validateManifestChecksums(checksums types.Map) (diags diag.Diagnostics) {
+	if checksums.IsUnknown() {
+		return nil
+	}
+	elements := checksums.Elements()
+	keys := make([]string, 0, len(elements))
+	for key := range elements {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		attr := path.Root("manifest_sha256").AtMapKey(key)
+		if key != "crds" && key != "olm" {
+			diags.AddAttributeError(attr, "Invalid manifest", fmt.Sprintf("%q is not an OLM manifest, "+
+				"checksums are set for crds or olm", key))
+			continue
+		}
+		if checksum := elements[key].(types.String); !checksum.IsUnknown() && !sha256Pattern.MatchString(checksum.ValueString()) {
+			diags.AddAttributeError(attr, "Invalid checksum", fmt.Sprintf("%q is not a hex encoded "+
+				"sha256 checksum", checksum.ValueString()))
+		}
+	}
+	return diags
+}
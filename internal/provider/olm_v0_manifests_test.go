@@ -0,0 +1,43 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestValidateManifestChecksums(t *testing.T) {
+	checksum := "f93a1b83c9e035ab4d334e70f9f4496549752786ae38919f4061e44bdddae510"
+	valid := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"crds": types.StringValue("sha256:" + checksum),
+		"olm":  types.StringValue(checksum),
+	})
+	if diags := validateManifestChecksums(valid); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if opts := manifestChecksums(valid); opts.CRDs != "sha256:"+checksum || opts.OLM != checksum {
+		t.Errorf("checksums = %+v", opts)
+	}
+
+	if diags := validateManifestChecksums(types.MapUnknown(types.StringType)); diags.HasError() {
+		t.Fatalf("unexpected diagnostics for unknown checksums: %v", diags)
+	}
+
+	invalid := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"olm":    types.StringValue("md5:d41d8cd98f00b204e9800998ecf8427e"),
+		"bundle": types.StringValue(checksum),
+	})
+	diags := validateManifestChecksums(invalid)
+	if diags.ErrorsCount() != 2 {
+		t.Fatalf("got %d errors, want 2: %v", diags.ErrorsCount(), diags)
+	}
+	for i, key := range []string{"bundle", "olm"} {
+		want := path.Root("manifest_sha256").AtMapKey(key)
+		if got := diags[i].(diag.DiagnosticWithPath).Path(); !got.Equal(want) {
+			t.Errorf("diagnostic %d path = %s, want %s", i, got, want)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package provider
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+)
+
+// OLMConfigModel configures the cluster OLMConfig.
+type OLMConfigModel struct {
+	DisableCopiedCSVs         types.Bool   `tfsdk:"disable_copied_csvs"`
+	PackageServerSyncInterval types.String `tfsdk:"package_server_sync_interval"`
+}
+
+// syncIntervalPattern matches the durations the OLMConfig CRD accepts, in
+// hours, minutes and seconds only.
+var syncIntervalPattern = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?(s|m|h))+$`)
+
+// options returns the installer OLMConfig options described by m.
+func (m *OLMConfigModel) options() installer.OLMConfigOptions {
+	if m == nil {
+		return installer.OLMConfigOptions{}
+	}
+	opts := installer.OLMConfigOptions{PackageServerSyncInterval: m.PackageServerSyncInterval.ValueString()}
+	if !m.DisableCopiedCSVs.IsNull() && !m.DisableCopiedCSVs.IsUnknown() {
+		disabled := m.DisableCopiedCSVs.ValueBool()
+		opts.DisableCopiedCSVs = &disabled
+	}
+	return opts
+}
+
+// refresh updates the settings of m with the configuration found on the
+// cluster. Settings left unset keep the value OLM defaults them to, they're
+// not owned by the provider. An interval written differently but of the same
+// duration, e.g. "1h" for "60m", is kept.
+func (m *OLMConfigModel) refresh(live installer.OLMConfigOptions) {
+	if m == nil {
+		return
+	}
+	if !m.DisableCopiedCSVs.IsNull() {
+		m.DisableCopiedCSVs = types.BoolPointerValue(live.DisableCopiedCSVs)
+	}
+	if m.PackageServerSyncInterval.IsNull() {
+		return
+	}
+
+	current, err := time.ParseDuration(m.PackageServerSyncInterval.ValueString())
+	if d, liveErr := time.ParseDuration(live.PackageServerSyncInterval); err == nil && liveErr == nil && d == current {
+		return
+	}
+	m.PackageServerSyncInterval = types.StringNull()
+	if live.PackageServerSyncInterval != "" {
+		m.PackageServerSyncInterval = types.StringValue(live.PackageServerSyncInterval)
+	}
+}
+
+// validate reports a sync interval the OLMConfig CRD rejects.
+func (m *OLMConfigModel) validate(block path.Path) (diags diag.Diagnostics) {
+	if m == nil || m.PackageServerSyncInterval.IsNull() || m.PackageServerSyncInterval.IsUnknown() {
+		return nil
+	}
+	if !syncIntervalPattern.MatchString(m.PackageServerSyncInterval.ValueString()) {
+		diags.AddAttributeError(block.AtName("package_server_sync_interval"), "Invalid package server sync interval",
+			fmt.Sprintf("package_server_sync_interval must be a duration in hours, minutes or seconds such as "+
+				"60m, got %q", m.PackageServerSyncInterval.ValueString()))
+	}
+	return diags
+}
+
+// validateRelease reports an OLMConfig configured for an OLM release
+// released without one. Replaced olm.yaml manifests may have one.
+func (m *OLMConfigModel) validateRelease(block path.Path, release string, sources installer.ManifestSources) (diags diag.Diagnostics) {
+	if m == nil || sources.OLM != "" || installer.SupportsOLMConfig(release) {
+		return nil
+	}
+	diags.AddAttributeError(block, "Unsupported OLMConfig",
+		fmt.Sprintf("OLM %s has no OLMConfig, it's released with OLM %s and later. Remove the olm_config "+
+			"block or upgrade OLM.", release, installer.OLMConfigMinVersion))
+	return diags
+}
+
+// olmConfigBlock returns the schema of the olm_config block of olm_v0_instance.
+func olmConfigBlock() schema.SingleNestedBlock {
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "The cluster-wide `" + installer.OLMConfigName + "` OLMConfig, released with OLM " +
+			"0.24 and later, older releases fail the plan. Unset settings keep the defaults of OLM and aren't " +
+			"tracked. Changes made on the cluster to the settings set are detected and reverted. Changing it " +
+			"updates OLM in place",
+		Attributes: map[string]schema.Attribute{
+			"disable_copied_csvs": schema.BoolAttribute{
+				MarkdownDescription: "Stop copying the ClusterServiceVersions of operators watching all " +
+					"namespaces into every namespace. The copies are deleted when disabled, which relieves " +
+					"etcd on clusters with many namespaces",
+				Optional: true,
+			},
+			"package_server_sync_interval": schema.StringAttribute{
+				MarkdownDescription: "How often packageserver checks the CatalogSources, a duration in " +
+					"hours, minutes or seconds such as `60m`",
+				Optional: true,
+			},
+		},
+	}
+}
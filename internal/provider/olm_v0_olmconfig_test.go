@@ -0,0 +1,87 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+)
+
+func TestOLMConfigRefresh(t *testing.T) {
+	disabled := true
+	for _, tc := range []struct {
+		name  string
+		state OLMConfigModel
+		live  installer.OLMConfigOptions
+		want  OLMConfigModel
+	}{
+		{
+			name:  "unchanged",
+			state: OLMConfigModel{DisableCopiedCSVs: types.BoolValue(true), PackageServerSyncInterval: types.StringValue("60m")},
+			live:  installer.OLMConfigOptions{DisableCopiedCSVs: &disabled, PackageServerSyncInterval: "60m"},
+			want:  OLMConfigModel{DisableCopiedCSVs: types.BoolValue(true), PackageServerSyncInterval: types.StringValue("60m")},
+		},
+		{
+			name:  "same duration",
+			state: OLMConfigModel{PackageServerSyncInterval: types.StringValue("60m")},
+			live:  installer.OLMConfigOptions{PackageServerSyncInterval: "1h0m0s"},
+			want:  OLMConfigModel{DisableCopiedCSVs: types.BoolNull(), PackageServerSyncInterval: types.StringValue("60m")},
+		},
+		{
+			name:  "drifted",
+			state: OLMConfigModel{DisableCopiedCSVs: types.BoolValue(true), PackageServerSyncInterval: types.StringValue("60m")},
+			live:  installer.OLMConfigOptions{PackageServerSyncInterval: "5m"},
+			want:  OLMConfigModel{DisableCopiedCSVs: types.BoolNull(), PackageServerSyncInterval: types.StringValue("5m")},
+		},
+		{
+			name:  "unset",
+			state: OLMConfigModel{PackageServerSyncInterval: types.StringValue("60m")},
+			live:  installer.OLMConfigOptions{DisableCopiedCSVs: new(bool), PackageServerSyncInterval: "60m"},
+			want:  OLMConfigModel{DisableCopiedCSVs: types.BoolNull(), PackageServerSyncInterval: types.StringValue("60m")},
+		},
+		{
+			name:  "removed",
+			state: OLMConfigModel{PackageServerSyncInterval: types.StringValue("60m")},
+			live:  installer.OLMConfigOptions{},
+			want:  OLMConfigModel{DisableCopiedCSVs: types.BoolNull(), PackageServerSyncInterval: types.StringNull()},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.state.refresh(tc.live)
+			if !tc.state.DisableCopiedCSVs.Equal(tc.want.DisableCopiedCSVs) ||
+				!tc.state.PackageServerSyncInterval.Equal(tc.want.PackageServerSyncInterval) {
+				t.Errorf("refreshed to %+v, want %+v", tc.state, tc.want)
+			}
+		})
+	}
+}
+
+func TestOLMConfigValidate(t *testing.T) {
+	valid := &OLMConfigModel{PackageServerSyncInterval: types.StringValue("1h30m")}
+	if diags := valid.validate(path.Root("olm_config")); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	invalid := &OLMConfigModel{PackageServerSyncInterval: types.StringValue("500ms")}
+	if diags := invalid.validate(path.Root("olm_config")); diags.ErrorsCount() != 1 {
+		t.Fatalf("got %d errors, want 1: %v", diags.ErrorsCount(), diags)
+	}
+}
+
+func TestOLMConfigValidateRelease(t *testing.T) {
+	config := &OLMConfigModel{DisableCopiedCSVs: types.BoolValue(true)}
+	block := path.Root("olm_config")
+	if diags := config.validateRelease(block, "0.24.0", installer.ManifestSources{}); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if diags := config.validateRelease(block, "0.23.1", installer.ManifestSources{}); diags.ErrorsCount() != 1 {
+		t.Fatalf("got %d errors, want 1: %v", diags.ErrorsCount(), diags)
+	}
+	if diags := config.validateRelease(block, "0.23.1", installer.ManifestSources{OLM: "olm.yaml"}); diags.HasError() {
+		t.Fatalf("unexpected diagnostics for replaced manifests: %v", diags)
+	}
+	var unset *OLMConfigModel
+	if diags := unset.validateRelease(block, "0.23.1", installer.ManifestSources{}); diags.HasError() {
+		t.Fatalf("unexpected diagnostics without olm_config: %v", diags)
+	}
+}
@@ -3,16 +3,19 @@ package provider
 import (
 	"context"
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
 	olmapiv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"strings"
 )
 
 // Ensure provider defined interface is implemented.
 var _ resource.Resource = &Operatorv0Resource{}
+var _ resource.ResourceWithValidateConfig = &Operatorv0Resource{}
 
 // Operatorv0Resource struct.
 type Operatorv0Resource struct {
@@ -26,13 +29,14 @@ func NewOperatorv0Resource() resource.Resource {
 
 // Operatorv0ResourceModel represents the structure of the resource data.
 type Operatorv0ResourceModel struct {
-	Name                types.String `tfsdk:"name"`
-	Channel             types.String `tfsdk:"channel"`
-	Source              types.String `tfsdk:"source"`
-	SourceNamespace     types.String `tfsdk:"source_namespace"`
-	InstallPlanApproval types.String `tfsdk:"install_plan_approval"`
-	Namespace           types.String `tfsdk:"namespace"`
-	ID                  types.String `tfsdk:"id"`
+	Name                types.String   `tfsdk:"name"`
+	Channel             types.String   `tfsdk:"channel"`
+	Source              types.String   `tfsdk:"source"`
+	SourceNamespace     types.String   `tfsdk:"source_namespace"`
+	InstallPlanApproval types.String   `tfsdk:"install_plan_approval"`
+	Namespace           types.String   `tfsdk:"namespace"`
+	Timeouts            *TimeoutsModel `tfsdk:"timeouts"`
+	ID                  types.String   `tfsdk:"id"`
 }
 
 func (r *Operatorv0Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -54,10 +58,11 @@ func (r *Operatorv0Resource) Schema(ctx context.Context, req resource.SchemaRequ
 				Required:            true,
 			},
 			"source": schema.StringAttribute{
-				MarkdownDescription: "The source catalog of the Operator",
-				Optional:            true,
-				Default:             stringdefault.StaticString("operatorhubio-catalog"),
-				Computed:            true,
+				MarkdownDescription: "The source catalog of the Operator, defaults to the default catalog of OLM. " +
+					"Set it when the default catalog is disabled",
+				Optional: true,
+				Default:  stringdefault.StaticString(installer.DefaultCatalogName),
+				Computed: true,
 			},
 			"source_namespace": schema.StringAttribute{
 				MarkdownDescription: "The namespace where the Operator source catalog is installed",
@@ -84,6 +89,9 @@ func (r *Operatorv0Resource) Schema(ctx context.Context, req resource.SchemaRequ
 				Computed:            true,
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"timeouts": timeoutsBlock(operatorTimeouts),
+		},
 	}
 }
 
@@ -139,12 +147,11 @@ func (r *Operatorv0Resource) Create(ctx context.Context, req resource.CreateRequ
 	}
 
 	// Create the Operator
+	ctx, cancel := plan.Timeouts.create(ctx, operatorTimeouts)
+	defer cancel()
 	operatorStatus, err := client.InstallOperator(ctx, resources)
 	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to install Operator",
-			fmt.Sprintf("Failed to install Operator: %v", err),
-		)
+		addInstallerError(&resp.Diagnostics, "Failed to install Operator", err)
 		return
 	}
 
@@ -164,6 +171,7 @@ func (r *Operatorv0Resource) Create(ctx context.Context, req resource.CreateRequ
 		SourceNamespace:     plan.SourceNamespace,
 		InstallPlanApproval: plan.InstallPlanApproval,
 		Namespace:           plan.Namespace,
+		Timeouts:            plan.Timeouts,
 		ID:                  id,
 	})
 }
@@ -177,10 +185,8 @@ func (r *Operatorv0Resource) Read(ctx context.Context, req resource.ReadRequest,
 	}
 
 	client, err := r.provider.getClient()
-
-	// If client can't be initialized due to missing config, clear the state
 	if err != nil {
-		resp.State.RemoveResource(ctx)
+		resp.Diagnostics.AddError("Failed to get client", err.Error())
 		return
 	}
 
@@ -202,7 +208,9 @@ func (r *Operatorv0Resource) Read(ctx context.Context, req resource.ReadRequest,
 		return
 	}
 
-	// Get the current status
+	// Get the current status, which waits for the CSV to succeed
+	ctx, cancel := state.Timeouts.read(ctx, operatorTimeouts)
+	defer cancel()
 	status, err := client.GetSubscriptionStatus(ctx, resources)
 	if err != nil {
 		// The resource is not found, which we can assume is because it was deleted.
@@ -235,6 +243,13 @@ func (r *Operatorv0Resource) Read(ctx context.Context, req resource.ReadRequest,
 
 func (r *Operatorv0Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
 	//NoOp as we only support Automatic install plan approval, so there is nothing to update
+	// apart from the timeouts, which only live in the state
+	var plan Operatorv0ResourceModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("timeouts"), plan.Timeouts)...)
 }
 
 func (r *Operatorv0Resource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -270,9 +285,11 @@ func (r *Operatorv0Resource) Delete(ctx context.Context, req resource.DeleteRequ
 	}
 
 	// Delete Operator using OLM client
+	ctx, cancel := state.Timeouts.delete(ctx, operatorTimeouts)
+	defer cancel()
 	err = client.UninstallOperator(ctx, resources)
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to delete Operator", err.Error())
+		addInstallerError(&resp.Diagnostics, "Failed to delete Operator", err)
 		return
 	}
 
@@ -293,3 +310,13 @@ func (r *Operatorv0Resource) Delete(ctx context.Context, req resource.DeleteRequ
 	}
 	resp.State.RemoveResource(ctx)
 }
+
+// ValidateConfig reports invalid timeouts before they are used.
+func (r *Operatorv0Resource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config Operatorv0ResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(config.Timeouts.validate()...)
+}
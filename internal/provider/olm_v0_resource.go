@@ -2,16 +2,27 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringdefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
 	"strings"
 )
 
 // Ensure provider defined interface is implemented.
 var _ resource.Resource = &OLMv0Resource{}
+var _ resource.ResourceWithImportState = &OLMv0Resource{}
+var _ resource.ResourceWithValidateConfig = &OLMv0Resource{}
+var _ resource.ResourceWithModifyPlan = &OLMv0Resource{}
 
 // OLMv0Resource struct.
 type OLMv0Resource struct {
@@ -25,9 +36,75 @@ func NewOLMv0Resource() resource.Resource {
 
 // OlmV0ResourceModel represents the structure of the resource data.
 type Olmv0ResourceModel struct {
-	Namespace types.String `tfsdk:"namespace"`
-	Version   types.String `tfsdk:"version"`
-	ID        types.String `tfsdk:"id"`
+	Namespace               types.String        `tfsdk:"namespace"`
+	OperatorsNamespace      types.String        `tfsdk:"operators_namespace"`
+	Version                 types.String        `tfsdk:"version"`
+	ResolvedVersion         types.String        `tfsdk:"resolved_version"`
+	ResolveTrigger          types.String        `tfsdk:"resolve_trigger"`
+	AdoptExisting           types.Bool          `tfsdk:"adopt_existing"`
+	ForceDestroy            types.Bool          `tfsdk:"force_destroy"`
+	StripFinalizers         types.Bool          `tfsdk:"strip_finalizers"`
+	Images                  *OLMImagesModel     `tfsdk:"images"`
+	OLMOperator             *OLMDeploymentModel `tfsdk:"olm_operator"`
+	CatalogOperator         *OLMDeploymentModel `tfsdk:"catalog_operator"`
+	PackageServer           *OLMDeploymentModel `tfsdk:"packageserver"`
+	DefaultCatalog          *OLMCatalogModel    `tfsdk:"default_catalog"`
+	OLMConfig               *OLMConfigModel     `tfsdk:"olm_config"`
+	Manifests               *OLMManifestsModel  `tfsdk:"manifests"`
+	ManifestSHA256          types.Map           `tfsdk:"manifest_sha256"`
+	ManifestTrustOnFirstUse types.Bool          `tfsdk:"manifest_trust_on_first_use"`
+	Timeouts                *TimeoutsModel      `tfsdk:"timeouts"`
+	Status                  types.String        `tfsdk:"status"`
+	ID                      types.String        `tfsdk:"id"`
+}
+
+// Health of an OLM installation, as reported by the status attribute.
+const (
+	olmStatusHealthy  = "healthy"
+	olmStatusDegraded = "degraded"
+)
+
+// release returns the OLM release m installs. State from before
+// resolved_version existed has the release in version.
+func (m Olmv0ResourceModel) release() string {
+	if v := m.ResolvedVersion.ValueString(); v != "" {
+		return v
+	}
+	return m.Version.ValueString()
+}
+
+// resolve sets resolved_version when it was unknown at plan time, e.g.
+// because version was.
+func (m *Olmv0ResourceModel) resolve(ctx context.Context, client *installer.Client) error {
+	if !m.ResolvedVersion.IsUnknown() {
+		return nil
+	}
+	release, err := client.ResolveVersion(ctx, m.Version.ValueString())
+	if err != nil {
+		return err
+	}
+	m.ResolvedVersion = types.StringValue(release)
+	return nil
+}
+
+// installOptions returns the installer options for the OLM installation described by m.
+func (m Olmv0ResourceModel) installOptions() installer.InstallOptions {
+	return installer.InstallOptions{
+		Version:            m.release(),
+		Namespace:          m.Namespace.ValueString(),
+		OperatorsNamespace: m.OperatorsNamespace.ValueString(),
+		AdoptExisting:      m.AdoptExisting.ValueBool(),
+		StripFinalizers:    m.StripFinalizers.ValueBool(),
+		Images:             m.Images.overrides(),
+		OLMOperator:        m.OLMOperator.options(),
+		CatalogOperator:    m.CatalogOperator.options(),
+		PackageServer:      m.PackageServer.options(),
+		DefaultCatalog:     m.DefaultCatalog.options(),
+		OLMConfig:          m.OLMConfig.options(),
+		Manifests:          m.Manifests.sources(),
+		Checksums:          manifestChecksums(m.ManifestSHA256),
+		TrustOnFirstUse:    m.ManifestTrustOnFirstUse.ValueBool(),
+	}
 }
 
 func (r *OLMv0Resource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
@@ -41,22 +118,122 @@ func (r *OLMv0Resource) Schema(ctx context.Context, req resource.SchemaRequest,
 
 		Attributes: map[string]schema.Attribute{
 			"namespace": schema.StringAttribute{
-				MarkdownDescription: "The namespace where to install olm",
-				Optional:            true,
-				Default:             stringdefault.StaticString("olm"),
-				Computed:            true,
+				MarkdownDescription: "The namespace where to install olm, it's also the namespace of the " +
+					"global catalogs. Changing it reinstalls OLM",
+				Optional: true,
+				Default:  stringdefault.StaticString(installer.DefaultOLMNamespace),
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			// State from before operators_namespace existed has it unset, adopt the default in place.
+			"operators_namespace": schema.StringAttribute{
+				MarkdownDescription: "The namespace of the global OperatorGroup, where operators watching " +
+					"all namespaces are installed. Changing it reinstalls OLM",
+				Optional: true,
+				Default:  stringdefault.StaticString(installer.DefaultOperatorsNamespace),
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplaceIf(func(ctx context.Context, req planmodifier.StringRequest, resp *stringplanmodifier.RequiresReplaceIfFuncResponse) {
+						resp.RequiresReplace = !req.StateValue.IsNull()
+					}, "Changing the operators namespace reinstalls OLM",
+						"Changing the operators namespace reinstalls OLM"),
+				},
 			},
 			"version": schema.StringAttribute{
-				MarkdownDescription: "OLM version to install v0 only",
-				Optional:            true,
-				Default:             stringdefault.StaticString(OLMv0Version),
-				Computed:            true,
+				MarkdownDescription: "OLM version to install v0 only, with or without a `v` prefix, `latest`, or a " +
+					"constraint such as `~> 0.25.0` resolved to the newest matching release. A constraint must " +
+					"resolve to a bundled release unless `manifest_sha256` or `manifest_trust_on_first_use` is set. " +
+					"Defaults to " + OLMv0Version + ", which is bundled with the provider. " +
+					"Other versions are downloaded, they're checked to be published when planned. " +
+					"Changing the version upgrades OLM in place",
+				Optional: true,
+				Default:  stringdefault.StaticString(OLMv0Version),
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					sameVersionModifier{},
+				},
+			},
+			"resolved_version": schema.StringAttribute{
+				MarkdownDescription: "The OLM release installed, `version` resolved among the bundled and the " +
+					"published releases. It is kept while it matches `version`, so new releases don't change " +
+					"the plan, and is resolved again when `resolve_trigger` changes",
+				Computed: true,
+			},
+			"resolve_trigger": schema.StringAttribute{
+				MarkdownDescription: "Any value, changing it resolves `version` again, e.g. to upgrade to the " +
+					"`latest` release",
+				Optional: true,
+			},
+			"adopt_existing": schema.BoolAttribute{
+				MarkdownDescription: "Take over an OLM installation that is already on the cluster, e.g. one " +
+					"left by operator-sdk or a half-finished uninstall, instead of failing. The existing objects " +
+					"must match the manifests of `version`, otherwise the differences are reported. " +
+					"Missing objects are created",
+				Optional: true,
+				Default:  booldefault.StaticBool(false),
+				Computed: true,
+			},
+			"manifest_sha256": schema.MapAttribute{
+				MarkdownDescription: "Expected sha256 checksums of the `crds` and `olm` manifests, e.g. " +
+					"`{ olm = \"sha256:...\" }`. The manifests of the releases bundled with the provider are " +
+					"verified against embedded checksums. Other downloaded manifests, including `manifests` " +
+					"URLs, must have their checksum set unless `manifest_trust_on_first_use` is, and files or " +
+					"inline manifests are verified when it is. Manifests failing verification are never applied",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"manifest_trust_on_first_use": schema.BoolAttribute{
+				MarkdownDescription: "Accept downloaded manifests without a checksum in `manifest_sha256` as long " +
+					"as they match their first download. The checksum of the first download is logged and kept " +
+					"in the manifest cache, so a later download that differs fails until it's reviewed and " +
+					"pinned. Requires the manifest cache of the provider",
+				Optional: true,
+				Default:  booldefault.StaticBool(false),
+				Computed: true,
+			},
+			"force_destroy": schema.BoolAttribute{
+				MarkdownDescription: "Uninstall OLM even though operators are installed through it. Uninstalling " +
+					"OLM deletes its CRDs, and with them every Subscription, ClusterServiceVersion and InstallPlan " +
+					"on the cluster, so by default destroying the instance fails while any are left. " +
+					"It must be applied before the destroy to take effect",
+				Optional: true,
+				Default:  booldefault.StaticBool(false),
+				Computed: true,
+			},
+			"strip_finalizers": schema.BoolAttribute{
+				MarkdownDescription: "Remove the finalizers that keep OLM objects, including namespaces stuck " +
+					"terminating, from being deleted when OLM is uninstalled. By default the uninstall fails " +
+					"and lists the stuck objects with their finalizers. " +
+					"It must be applied before the destroy to take effect",
+				Optional: true,
+				Default:  booldefault.StaticBool(false),
+				Computed: true,
+			},
+			"status": schema.StringAttribute{
+				MarkdownDescription: "Health of the OLM installation: `" + olmStatusHealthy + "` when all of its " +
+					"resources are present, `" + olmStatusDegraded + "` when some of them are missing",
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
 			},
 			"id": schema.StringAttribute{
 				MarkdownDescription: "The ID of the OLM resource",
 				Computed:            true,
 			},
 		},
+		Blocks: map[string]schema.Block{
+			"images":           imagesBlock(),
+			"olm_operator":     deploymentBlock("olm-operator"),
+			"catalog_operator": deploymentBlock("catalog-operator"),
+			"packageserver":    deploymentBlock("packageserver"),
+			"default_catalog":  catalogBlock(),
+			"olm_config":       olmConfigBlock(),
+			"manifests":        manifestsBlock(),
+			"timeouts":         timeoutsBlock(olmTimeouts),
+		},
 	}
 }
 
@@ -92,10 +269,19 @@ func (r *OLMv0Resource) Create(ctx context.Context, req resource.CreateRequest,
 		return
 	}
 
-	olmStatus, err := client.InstallVersion(ctx, plan.Namespace.ValueString(), plan.Version.ValueString())
-	if err != nil {
-		resp.Diagnostics.AddError(
-			"Failed to install OLM", err.Error())
+	ctx, cancel := plan.Timeouts.create(ctx, olmTimeouts)
+	defer cancel()
+	if err := plan.resolve(ctx, client); err != nil {
+		addInstallerError(&resp.Diagnostics, "Failed to resolve the OLM version", err)
+		return
+	}
+	olmStatus, err := client.InstallVersion(ctx, plan.installOptions())
+	if errors.Is(err, installer.ErrExistingOLM) {
+		resp.Diagnostics.AddAttributeError(path.Root("adopt_existing"), "Failed to install OLM",
+			err.Error()+". Set adopt_existing to take over the existing installation, or import it with terraform import")
+		return
+	} else if err != nil {
+		addInstallerError(&resp.Diagnostics, "Failed to install OLM", err)
 		return
 	}
 
@@ -109,9 +295,26 @@ func (r *OLMv0Resource) Create(ctx context.Context, req resource.CreateRequest,
 	// Set resource ID and state on successful creation
 	id := "olm"
 	resp.State.Set(ctx, &Olmv0ResourceModel{
-		Namespace: plan.Namespace,
-		Version:   plan.Version,
-		ID:        types.StringValue(id),
+		Namespace:               plan.Namespace,
+		OperatorsNamespace:      plan.OperatorsNamespace,
+		Version:                 plan.Version,
+		ResolvedVersion:         plan.ResolvedVersion,
+		ResolveTrigger:          plan.ResolveTrigger,
+		AdoptExisting:           plan.AdoptExisting,
+		ForceDestroy:            plan.ForceDestroy,
+		StripFinalizers:         plan.StripFinalizers,
+		Images:                  plan.Images,
+		OLMOperator:             plan.OLMOperator,
+		CatalogOperator:         plan.CatalogOperator,
+		PackageServer:           plan.PackageServer,
+		DefaultCatalog:          plan.DefaultCatalog,
+		OLMConfig:               plan.OLMConfig,
+		Manifests:               plan.Manifests,
+		ManifestSHA256:          plan.ManifestSHA256,
+		ManifestTrustOnFirstUse: plan.ManifestTrustOnFirstUse,
+		Timeouts:                plan.Timeouts,
+		Status:                  types.StringValue(olmStatusHealthy),
+		ID:                      types.StringValue(id),
 	})
 }
 
@@ -124,41 +327,94 @@ func (r *OLMv0Resource) Read(ctx context.Context, req resource.ReadRequest, resp
 	}
 
 	client, err := r.provider.getClient()
-
-	// If client can't be initialized due to missing config, clear the state
 	if err != nil {
-		resp.State.RemoveResource(ctx)
+		resp.Diagnostics.AddError("Failed to get client", err.Error())
+		return
+	}
+
+	ctx, cancel := state.Timeouts.read(ctx, olmTimeouts)
+	defer cancel()
+
+	// Resolve the version actually running and where, OLM may have been upgraded or moved out of band.
+	// The status is checked against the release of the state, which was verified when it was installed,
+	// as the manifests of a drifted release may not be available.
+	statusOpts := state.installOptions()
+	namespace, version, err := client.FindInstalledVersion(ctx, state.Namespace.ValueString())
+	switch {
+	case err == nil:
+		if namespace != state.Namespace.ValueString() {
+			tflog.Info(ctx, "Detected OLM namespace drift", map[string]interface{}{
+				"state_namespace": state.Namespace.ValueString(), "installed_namespace": namespace})
+			state.Namespace = types.StringValue(namespace)
+			statusOpts.Namespace = namespace
+		}
+		if !installer.SameVersion(version, state.release()) {
+			tflog.Info(ctx, "Detected OLM version drift", map[string]interface{}{
+				"state_version": state.release(), "installed_version": version})
+			state.ResolvedVersion = types.StringValue(installer.CanonicalVersion(version))
+			// A constraint is kept, the plan tells whether the installed release still matches it
+			if !installer.IsVersionConstraint(state.Version.ValueString()) {
+				state.Version = state.ResolvedVersion
+			}
+		}
+	case errors.Is(err, olmresourceclient.ErrOLMNotInstalled):
+		// Without the packageserver CSV the version is unknown, keep the one in the state
+		// and let the status check below tell a partial installation from a removed one.
+	default:
+		resp.Diagnostics.AddError("Error reading the installed OLM version", err.Error())
 		return
 	}
 
-	// Get the current status
-	status, err := client.GetStatus(ctx, state.Version.ValueString())
+	status, err := client.GetStatus(ctx, statusOpts)
 	if err != nil {
-		// The resource is not found, which we can assume is because it was deleted.
-		// Remove the resource from the state and return.
-		if strings.Contains(err.Error(), "the server could not find the requested resource") {
+		// Nothing of the installation is left, it was deleted out of band.
+		if errors.Is(err, olmresourceclient.ErrOLMNotInstalled) {
 			resp.State.RemoveResource(ctx)
 			return
 		}
-		// For other errors, report them back to Terraform.
-		resp.Diagnostics.AddError("Error reading OLM status", err.Error())
+		addInstallerError(&resp.Diagnostics, "Error reading OLM status", err)
 		return
 	}
 
-	// Use the HasInstalledResources method to determine if the resources are installed
-	installed, err := status.HasInstalledResources()
-	if err != nil {
-		resp.Diagnostics.AddError("Error checking OLM installation status", err.Error())
-		return
+	// Detect changes made to the managed OLMConfig settings on the cluster
+	if state.OLMConfig != nil {
+		olmConfig, err := client.GetOLMConfig(ctx)
+		if err != nil {
+			resp.Diagnostics.AddError("Error reading the OLMConfig", err.Error())
+			return
+		}
+		state.OLMConfig.refresh(olmConfig)
 	}
 
-	if !installed {
-		// If OLM is not installed, remove the resource from the state
-		resp.State.RemoveResource(ctx)
-		return
+	if state.ResolvedVersion.IsNull() && !installer.IsVersionConstraint(state.Version.ValueString()) {
+		state.ResolvedVersion = types.StringValue(installer.CanonicalVersion(state.Version.ValueString()))
+	}
+	// State from before adopt_existing, force_destroy, strip_finalizers and
+	// manifest_trust_on_first_use existed has them unset
+	for _, attr := range []*types.Bool{&state.AdoptExisting, &state.ForceDestroy, &state.StripFinalizers, &state.ManifestTrustOnFirstUse} {
+		if attr.IsNull() {
+			*attr = types.BoolValue(false)
+		}
+	}
+	state.Status = types.StringValue(olmStatusHealthy)
+	if missing := status.MissingResources(); len(missing) > 0 {
+		state.Status = types.StringValue(olmStatusDegraded)
+		names := make([]string, 0, len(missing))
+		for _, r := range missing {
+			name := strings.ToLower(r.GVK.Kind) + "/" + r.NamespacedName.Name
+			if r.NamespacedName.Namespace != "" {
+				name = r.NamespacedName.Namespace + "/" + name
+			}
+			names = append(names, name)
+		}
+		resp.Diagnostics.AddWarning("OLM installation is degraded",
+			fmt.Sprintf("%d resources of OLM %s are missing: %s. Reinstall OLM with "+
+				"`terraform apply -replace` to restore them.",
+				len(missing), statusOpts.Version, strings.Join(names, ", ")))
 	}
-	// Update the state - resources are present
-	resp.State.Set(ctx, &state)
+
+	// Update the state with the installation found on the cluster
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 }
 
 func (r *OLMv0Resource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
@@ -182,34 +438,38 @@ func (r *OLMv0Resource) Update(ctx context.Context, req resource.UpdateRequest,
 		return
 	}
 
-	// Check if the version has changed
-	if plan.Version != state.Version {
-		// Uninstall the current version
-		err := client.UninstallVersion(ctx, state.Version.ValueString())
-		if err != nil {
-			resp.Diagnostics.AddError("Failed to uninstall the current OLM version", err.Error())
-			return
-		}
-		// Install the new version
-		olmStatus, err := client.InstallVersion(ctx, plan.Namespace.ValueString(), plan.Version.ValueString())
+	if err := plan.resolve(ctx, client); err != nil {
+		addInstallerError(&resp.Diagnostics, "Failed to resolve the OLM version", err)
+		return
+	}
+
+	// Check if the release has changed, "v0.26.0" and "0.26.0" are the same release,
+	// or if the manifests are customized differently
+	from, to := state.installOptions(), plan.installOptions()
+	if !installer.SameInstallation(from, to) {
+		// Update in place, so operators installed through OLM keep running
+		ctx, cancel := plan.Timeouts.update(ctx, olmTimeouts)
+		defer cancel()
+		olmStatus, err := client.UpgradeVersion(ctx, from, to)
 		if err != nil {
-			resp.Diagnostics.AddError("Failed to install the new OLM version", err.Error())
+			addInstallerError(&resp.Diagnostics, "Failed to update OLM", err)
 			return
 		}
 
 		installed, err := olmStatus.HasInstalledResources()
 		if err != nil || !installed {
 			resp.Diagnostics.AddError("OLM installation verification failed",
-				"Failed to install the new OLM version. OLM resources are not installed as expected")
+				"Failed to update OLM. OLM resources are not installed as expected")
 			return
 		}
-
-		// Update the state with the new version
-		state.Version = plan.Version
 	}
 
+	// Keep the values as configured, the version may only differ from the state in its format
+	plan.ID = state.ID
+	plan.Status = types.StringValue(olmStatusHealthy)
+
 	// Update the Terraform state
-	diags = resp.State.Set(ctx, &state)
+	diags = resp.State.Set(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
 }
 
@@ -227,26 +487,190 @@ func (r *OLMv0Resource) Delete(ctx context.Context, req resource.DeleteRequest,
 		return
 	}
 
+	ctx, cancel := state.Timeouts.delete(ctx, olmTimeouts)
+	defer cancel()
+
+	// Deleting the OLM CRDs cascades to every operator installed through OLM
+	if !state.ForceDestroy.ValueBool() {
+		operators, err := client.ListOperators(ctx, state.installOptions())
+		if err != nil {
+			resp.Diagnostics.AddError("Failed to list the operators installed through OLM", err.Error())
+			return
+		}
+		if !operators.Empty() {
+			resp.Diagnostics.AddAttributeError(path.Root("force_destroy"), "Refusing to uninstall OLM",
+				fmt.Sprintf("Operators are still installed through OLM: %s. Uninstalling OLM deletes its CRDs, "+
+					"and with them every Subscription and ClusterServiceVersion on the cluster. Remove the "+
+					"operators first, or set force_destroy = true and apply it before destroying the instance.",
+					operators))
+			return
+		}
+	}
+
 	// Delete OLM using OLM client
-	err = client.UninstallVersion(ctx, state.Version.ValueString())
+	err = client.UninstallVersion(ctx, state.installOptions())
 	if err != nil {
-		resp.Diagnostics.AddError("Failed to delete OLM", err.Error())
+		addInstallerError(&resp.Diagnostics, "Failed to delete OLM", err)
+		return
+	}
+
+	// Verify the deletion through the packageserver CSV, the manifests of the release may not be available
+	_, err = client.GetInstalledVersion(ctx, state.Namespace.ValueString())
+	if err != nil && !errors.Is(err, olmresourceclient.ErrOLMNotInstalled) {
+		resp.Diagnostics.AddError("Error getting OLM status", err.Error())
+		return
+	}
+	resp.State.RemoveResource(ctx)
+}
+
+// ValidateConfig reports invalid versions, deployment tuning, catalog settings, OLMConfig settings, checksums and
+// timeouts before they reach the cluster.
+func (r *OLMv0Resource) ValidateConfig(ctx context.Context, req resource.ValidateConfigRequest, resp *resource.ValidateConfigResponse) {
+	var config Olmv0ResourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &config)...)
+	if resp.Diagnostics.HasError() {
 		return
 	}
+	resp.Diagnostics.Append(config.OLMOperator.validate(path.Root("olm_operator"))...)
+	resp.Diagnostics.Append(config.CatalogOperator.validate(path.Root("catalog_operator"))...)
+	resp.Diagnostics.Append(config.PackageServer.validate(path.Root("packageserver"))...)
+	resp.Diagnostics.Append(config.DefaultCatalog.validate(path.Root("default_catalog"))...)
+	resp.Diagnostics.Append(config.OLMConfig.validate(path.Root("olm_config"))...)
+	resp.Diagnostics.Append(validateManifestChecksums(config.ManifestSHA256)...)
+	resp.Diagnostics.Append(config.Timeouts.validate()...)
+	if !config.Version.IsNull() && !config.Version.IsUnknown() {
+		if err := installer.ValidateVersion(config.Version.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(path.Root("version"), "Invalid OLM version", err.Error())
+		}
+	}
+}
 
-	// Get the current status to verify deletion
-	_, err = client.GetStatus(ctx, state.Version.ValueString())
+// ModifyPlan resolves version to the release recorded in resolved_version.
+// The release resolved before is kept while it matches version, unless
+// resolve_trigger changed, so that new releases don't change the plan. A new
+// release whose manifests are neither bundled with the provider nor published
+// at the release source is reported at plan time, rather than halfway through
+// the apply. It can't be checked when both manifests are replaced. So is an
+// olm_config block for a release without an OLMConfig.
+func (r *OLMv0Resource) ModifyPlan(ctx context.Context, req resource.ModifyPlanRequest, resp *resource.ModifyPlanResponse) {
+	if req.Plan.Raw.IsNull() || r.provider == nil || r.provider.config == nil {
+		return
+	}
+	var version, trigger types.String
+	var manifests *OLMManifestsModel
+	var checksums types.Map
+	var trust types.Bool
+	var olmConfig *OLMConfigModel
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("version"), &version)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("resolve_trigger"), &trigger)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("manifests"), &manifests)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("manifest_sha256"), &checksums)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("manifest_trust_on_first_use"), &trust)...)
+	resp.Diagnostics.Append(req.Plan.GetAttribute(ctx, path.Root("olm_config"), &olmConfig)...)
+	if resp.Diagnostics.HasError() || version.IsNull() {
+		return
+	}
+	if version.IsUnknown() || trigger.IsUnknown() {
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("resolved_version"), types.StringUnknown())...)
+		return
+	}
+	var resolved, stateTrigger types.String
+	if !req.State.Raw.IsNull() {
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("resolved_version"), &resolved)...)
+		resp.Diagnostics.Append(req.State.GetAttribute(ctx, path.Root("resolve_trigger"), &stateTrigger)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	release := resolved.ValueString()
+	if release != "" && installer.VersionMatches(release, version.ValueString()) && trigger.Equal(stateTrigger) {
+		resp.Diagnostics.Append(olmConfig.validateRelease(path.Root("olm_config"), release, manifests.sources())...)
+		resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("resolved_version"), release)...)
+		return
+	}
+
+	client, err := r.provider.getDownloadClient()
 	if err != nil {
-		// The resource is already deleted/not found, which is the desired outcome.
-		// Remove the resource from the state and return.
-		if strings.Contains(err.Error(), "no existing installation found") {
-			resp.State.RemoveResource(ctx)
+		resp.Diagnostics.AddError("Failed to get client", err.Error())
+		return
+	}
+	release, err = client.ResolveVersion(ctx, version.ValueString())
+	if err != nil {
+		addInstallerError(&resp.Diagnostics, "Failed to resolve the OLM version", err)
+		return
+	}
+	resp.Diagnostics.Append(olmConfig.validateRelease(path.Root("olm_config"), release, manifests.sources())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	// A constraint mustn't pick a release whose manifests can't be verified, unless they're explicitly
+	// trusted on first use
+	if installer.IsVersionConstraint(version.ValueString()) && !checksums.IsUnknown() && !trust.ValueBool() {
+		if !verifiableRelease(release, manifests.sources(), manifestChecksums(checksums)) {
+			resp.Diagnostics.AddAttributeError(path.Root("version"), "Unverified OLM version",
+				fmt.Sprintf("%q resolves to OLM %s, which isn't bundled with the provider, so its manifests "+
+					"can't be verified against known checksums. Set their checksums in manifest_sha256, set "+
+					"manifest_trust_on_first_use to trust them on first use, or narrow the constraint to the "+
+					"bundled versions: %s.", version.ValueString(), release,
+					strings.Join(installer.BundledVersions(), ", ")))
 			return
+		}
+	}
+	resp.Diagnostics.Append(resp.Plan.SetAttribute(ctx, path.Root("resolved_version"), release)...)
+	if installer.SameVersion(release, resolved.ValueString()) {
+		return
+	}
+	if sources := manifests.sources(); sources.CRDs != "" && sources.OLM != "" {
+		return
+	}
+
+	err = client.CheckVersion(ctx, release)
+	var versionErr *installer.VersionError
+	if errors.As(err, &versionErr) {
+		addInstallerError(&resp.Diagnostics, "Unsupported OLM version", err)
+	} else if err != nil {
+		resp.Diagnostics.AddAttributeWarning(path.Root("version"), "Failed to check the OLM version",
+			fmt.Sprintf("The release source couldn't be reached to check that OLM %s is published, "+
+				"it's checked again when the manifests are downloaded: %v", release, err))
+	}
+}
+
+// ImportState adopts an existing OLM installation, e.g. one installed by operator-sdk or by hand.
+// The import ID is the OLM namespace, optionally followed by the operators namespace as
+// "namespace/operators_namespace". The version is read from the packageserver CSV.
+func (r *OLMv0Resource) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	namespace, operatorsNamespace, _ := strings.Cut(req.ID, "/")
+	if namespace == "" {
+		namespace = installer.DefaultOLMNamespace
+	}
+	if operatorsNamespace == "" {
+		operatorsNamespace = installer.DefaultOperatorsNamespace
+	}
 
+	client, err := r.provider.getClient()
+	if err != nil {
+		resp.Diagnostics.AddError("Failed to get client", err.Error())
+		return
+	}
+	version, err := client.GetInstalledVersion(ctx, namespace)
+	if err != nil {
+		if errors.Is(err, olmresourceclient.ErrOLMNotInstalled) {
+			resp.Diagnostics.AddError("OLM not found",
+				fmt.Sprintf("No OLM installation was found in namespace %q", namespace))
+			return
 		}
-		// For other errors, report them back to Terraform.
-		resp.Diagnostics.AddError("Error getting OLM status", err.Error())
+		resp.Diagnostics.AddError("Failed to get the installed OLM version", err.Error())
 		return
 	}
-	resp.State.RemoveResource(ctx)
+
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("namespace"), namespace)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("operators_namespace"), operatorsNamespace)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("version"), installer.CanonicalVersion(version))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("resolved_version"), installer.CanonicalVersion(version))...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("adopt_existing"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("force_destroy"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("strip_finalizers"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("manifest_trust_on_first_use"), false)...)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), "olm")...)
 }
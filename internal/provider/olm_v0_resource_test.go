@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+)
+
+func TestReadRemovesMissingInstallation(t *testing.T) {
+	ctx := context.Background()
+	p := &OLMProvider{config: &OLMProviderModel{Host: types.StringValue("https://cluster.example.com")}}
+	config, err := p.config.restConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := configKey(config, p.config.downloadOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// An empty cluster, OLM was removed out of band
+	p.clients.clients = map[string]*installer.Client{key: {Client: &olmresourceclient.Client{
+		KubeClient: fake.NewClientBuilder().Build(),
+		Log:        tflogLogger{},
+	}}}
+
+	r := &OLMv0Resource{provider: p}
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	typ := schemaResp.Schema.Type().TerraformType(ctx)
+	state := tfsdk.State{
+		Schema: schemaResp.Schema,
+		Raw: objectValue(typ, map[string]tftypes.Value{
+			"version":          tftypes.NewValue(tftypes.String, "0.26.0"),
+			"resolved_version": tftypes.NewValue(tftypes.String, "0.26.0"),
+			"namespace":        tftypes.NewValue(tftypes.String, "olm"),
+		}),
+	}
+
+	resp := &resource.ReadResponse{State: state}
+	r.Read(ctx, resource.ReadRequest{State: state}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	if !resp.State.Raw.IsNull() {
+		t.Error("the missing installation wasn't removed from the state")
+	}
+}
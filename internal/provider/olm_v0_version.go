@@ -0,0 +1,47 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+)
+
+// sameVersionModifier keeps the version in the state when the default
+// version is the same release written differently, e.g. "v0.26.0" from
+// before the default dropped its "v" prefix, so that it doesn't churn.
+type sameVersionModifier struct{}
+
+var _ planmodifier.String = sameVersionModifier{}
+
+func (sameVersionModifier) Description(ctx context.Context) string {
+	return "Keeps the version in the state when it's the same release as the default version."
+}
+
+func (m sameVersionModifier) MarkdownDescription(ctx context.Context) string {
+	return m.Description(ctx)
+}
+
+func (sameVersionModifier) PlanModifyString(ctx context.Context, req planmodifier.StringRequest, resp *planmodifier.StringResponse) {
+	// A configured version must be planned as written
+	if !req.ConfigValue.IsNull() || req.StateValue.IsNull() || req.StateValue.IsUnknown() || req.PlanValue.IsUnknown() {
+		return
+	}
+	if installer.SameVersion(req.StateValue.ValueString(), req.PlanValue.ValueString()) {
+		resp.PlanValue = req.StateValue
+	}
+}
+
+// verifiableRelease reports whether the manifests of release are verified
+// against known checksums: it's bundled with the provider, or each of its
+// manifests is either replaced by a file or inline manifests, or has its
+// checksum in manifest_sha256. Downloads from a URL need the checksum too.
+func verifiableRelease(release string, sources installer.ManifestSources, checksums installer.ManifestChecksums) bool {
+	if installer.IsBundledVersion(release) {
+		return true
+	}
+	verified := func(source, checksum string) bool {
+		return checksum != "" || (source != "" && !installer.IsRemoteManifest(source))
+	}
+	return verified(sources.CRDs, checksums.CRDs) && verified(sources.OLM, checksums.OLM)
+}
@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
+)
+
+func TestSameVersionModifier(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		config types.String
+		state  types.String
+		want   string
+	}{
+		{name: "default with v prefix", config: types.StringNull(), state: types.StringValue("v0.26.0"), want: "v0.26.0"},
+		{name: "default upgraded", config: types.StringNull(), state: types.StringValue("v0.25.0"), want: OLMv0Version},
+		{name: "configured", config: types.StringValue(OLMv0Version), state: types.StringValue("v0.26.0"), want: OLMv0Version},
+		{name: "created", config: types.StringNull(), state: types.StringNull(), want: OLMv0Version},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := planmodifier.StringRequest{
+				ConfigValue: tc.config,
+				StateValue:  tc.state,
+				PlanValue:   types.StringValue(OLMv0Version),
+			}
+			if !tc.config.IsNull() {
+				req.PlanValue = tc.config
+			}
+			resp := &planmodifier.StringResponse{PlanValue: req.PlanValue}
+			sameVersionModifier{}.PlanModifyString(context.Background(), req, resp)
+			if resp.PlanValue.ValueString() != tc.want {
+				t.Errorf("planned %q, want %q", resp.PlanValue.ValueString(), tc.want)
+			}
+		})
+	}
+}
+
+func TestVerifiableRelease(t *testing.T) {
+	sum := "sha256:" + strings.Repeat("0", 64)
+	for _, tc := range []struct {
+		name      string
+		release   string
+		sources   installer.ManifestSources
+		checksums installer.ManifestChecksums
+		want      bool
+	}{
+		{name: "bundled", release: OLMv0Version, want: true},
+		{name: "published", release: "0.99.0"},
+		{name: "one checksum", release: "0.99.0", checksums: installer.ManifestChecksums{OLM: sum}},
+		{name: "checksums", release: "0.99.0", checksums: installer.ManifestChecksums{CRDs: sum, OLM: sum}, want: true},
+		{
+			name:      "replaced manifest",
+			release:   "0.99.0",
+			sources:   installer.ManifestSources{CRDs: "crds.yaml"},
+			checksums: installer.ManifestChecksums{OLM: sum},
+			want:      true,
+		},
+		{
+			name:      "replaced by a URL",
+			release:   "0.99.0",
+			sources:   installer.ManifestSources{CRDs: "https://example.com/crds.yaml"},
+			checksums: installer.ManifestChecksums{OLM: sum},
+		},
+		{
+			name:      "replaced by a pinned URL",
+			release:   "0.99.0",
+			sources:   installer.ManifestSources{CRDs: "https://example.com/crds.yaml"},
+			checksums: installer.ManifestChecksums{CRDs: sum, OLM: sum},
+			want:      true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := verifiableRelease(tc.release, tc.sources, tc.checksums); got != tc.want {
+				t.Errorf("verifiableRelease(%q) = %t, want %t", tc.release, got, tc.want)
+			}
+		})
+	}
+}
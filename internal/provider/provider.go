@@ -5,15 +5,16 @@ package provider
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	olmresourceclient "github.com/kaplan-michael/terraform-provider-olm/internal/olm/client"
 	"github.com/kaplan-michael/terraform-provider-olm/internal/olm/installer"
-	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 )
 
 // New is the factory function to return the provider.Provider implementation.
@@ -31,19 +32,42 @@ func New(version string) func() provider.Provider {
 type OLMProvider struct {
 	version string
 	config  *OLMProviderModel
-	client  *installer.Client
+	clients clientCache
 }
 
 // Ensure OLMProvider implements the provider.Provider interface.
 var _ provider.Provider = &OLMProvider{}
+var _ provider.ProviderWithValidateConfig = &OLMProvider{}
 
 // OLMProviderModel describes the provider configuration.
 type OLMProviderModel struct {
-	Kubeconfig        types.String `tfsdk:"kubeconfig"`
-	Host              types.String `tfsdk:"host"`
-	CACertificate     types.String `tfsdk:"ca_certificate"`
-	ClientCertificate types.String `tfsdk:"client_certificate"`
-	ClientKey         types.String `tfsdk:"client_key"`
+	Kubeconfig              types.String          `tfsdk:"kubeconfig"`
+	KubeconfigPath          types.String          `tfsdk:"kubeconfig_path"`
+	ConfigContext           types.String          `tfsdk:"config_context"`
+	ConfigContextCluster    types.String          `tfsdk:"config_context_cluster"`
+	ConfigContextAuthInfo   types.String          `tfsdk:"config_context_auth_info"`
+	Host                    types.String          `tfsdk:"host"`
+	CACertificate           types.String          `tfsdk:"ca_certificate"`
+	ClientCertificate       types.String          `tfsdk:"client_certificate"`
+	ClientKey               types.String          `tfsdk:"client_key"`
+	Token                   types.String          `tfsdk:"token"`
+	Username                types.String          `tfsdk:"username"`
+	Password                types.String          `tfsdk:"password"`
+	ImpersonateUser         types.String          `tfsdk:"impersonate_user"`
+	ImpersonateGroups       types.List            `tfsdk:"impersonate_groups"`
+	Insecure                types.Bool            `tfsdk:"insecure"`
+	TLSServerName           types.String          `tfsdk:"tls_server_name"`
+	ProxyURL                types.String          `tfsdk:"proxy_url"`
+	QPS                     types.Float64         `tfsdk:"qps"`
+	Burst                   types.Int64           `tfsdk:"burst"`
+	ManifestBaseURL         types.String          `tfsdk:"manifest_base_url"`
+	ManifestHTTPHeaders     types.Map             `tfsdk:"manifest_http_headers"`
+	GithubToken             types.String          `tfsdk:"github_token"`
+	ManifestCACertificate   types.String          `tfsdk:"manifest_ca_certificate"`
+	ManifestProxyURL        types.String          `tfsdk:"manifest_proxy_url"`
+	ManifestDownloadRetries types.Int64           `tfsdk:"manifest_download_retries"`
+	ManifestCacheDir        types.String          `tfsdk:"manifest_cache_dir"`
+	Exec                    *OLMProviderExecModel `tfsdk:"exec"`
 }
 
 func (p *OLMProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
@@ -55,35 +79,195 @@ func (p *OLMProvider) Schema(ctx context.Context, req provider.SchemaRequest, re
 	resp.Schema = schema.Schema{
 		Attributes: map[string]schema.Attribute{
 			"kubeconfig": schema.StringAttribute{
-				MarkdownDescription: "Kubeconfig raw file",
-				Optional:            true,
-				Sensitive:           true,
+				MarkdownDescription: "Kubeconfig raw file. " +
+					"Can be set with the `OLM_KUBECONFIG` environment variable",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"kubeconfig_path": schema.StringAttribute{
+				MarkdownDescription: "Path to a kubeconfig file. Multiple files can be merged by separating them " +
+					"the same way as in the `KUBECONFIG` environment variable. " +
+					"Can be set with the `KUBE_CONFIG_PATH` environment variable",
+				Optional: true,
+			},
+			"config_context": schema.StringAttribute{
+				MarkdownDescription: "Kubeconfig context to use instead of the current context. " +
+					"Can be set with the `KUBE_CTX` environment variable",
+				Optional: true,
+			},
+			"config_context_cluster": schema.StringAttribute{
+				MarkdownDescription: "Kubeconfig cluster to use for the selected context. " +
+					"Can be set with the `KUBE_CTX_CLUSTER` environment variable",
+				Optional: true,
+			},
+			"config_context_auth_info": schema.StringAttribute{
+				MarkdownDescription: "Kubeconfig user to use for the selected context. " +
+					"Can be set with the `KUBE_CTX_AUTH_INFO` environment variable",
+				Optional: true,
 			},
-			//"kubeconfig_path": schema.StringAttribute{
-			//	MarkdownDescription: "path to kubeconfig file",
-			//	Optional:            true,
-			//},
 			"host": schema.StringAttribute{
-				MarkdownDescription: "Kubernetes API server host",
-				Optional:            true,
+				MarkdownDescription: "Kubernetes API server host. " +
+					"Can be set with the `KUBE_HOST` environment variable",
+				Optional: true,
 			},
 			"ca_certificate": schema.StringAttribute{
-				MarkdownDescription: "Kubernetes API server CA certificate",
-				Optional:            true,
+				MarkdownDescription: "Kubernetes API server CA certificate. " +
+					"Can be set with the `KUBE_CLUSTER_CA_CERT_DATA` environment variable",
+				Optional: true,
 			},
 			"client_certificate": schema.StringAttribute{
-				MarkdownDescription: "Kubernetes API server client certificate",
-				Optional:            true,
+				MarkdownDescription: "Kubernetes API server client certificate. " +
+					"Can be set with the `KUBE_CLIENT_CERT_DATA` environment variable",
+				Optional: true,
 			},
 			"client_key": schema.StringAttribute{
-				MarkdownDescription: "Kubernetes API server client key",
+				MarkdownDescription: "Kubernetes API server client key. " +
+					"Can be set with the `KUBE_CLIENT_KEY_DATA` environment variable",
+				Optional: true,
+			},
+			"token": schema.StringAttribute{
+				MarkdownDescription: "Bearer token for authenticating to the Kubernetes API server. " +
+					"Can be set with the `KUBE_TOKEN` environment variable",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"username": schema.StringAttribute{
+				MarkdownDescription: "Username for basic authentication to the Kubernetes API server. " +
+					"Can be set with the `KUBE_USER` environment variable",
+				Optional: true,
+			},
+			"password": schema.StringAttribute{
+				MarkdownDescription: "Password for basic authentication to the Kubernetes API server. " +
+					"Can be set with the `KUBE_PASSWORD` environment variable",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"impersonate_user": schema.StringAttribute{
+				MarkdownDescription: "User to impersonate for all requests to the Kubernetes API server. " +
+					"Can be set with the `KUBE_IMPERSONATE_USER` environment variable",
+				Optional: true,
+			},
+			"impersonate_groups": schema.ListAttribute{
+				MarkdownDescription: "Groups to impersonate together with `impersonate_user`. " +
+					"Can be set with the `KUBE_IMPERSONATE_GROUPS` environment variable as a comma separated list",
+				ElementType: types.StringType,
+				Optional:    true,
+			},
+			"insecure": schema.BoolAttribute{
+				MarkdownDescription: "Skip verification of the Kubernetes API server certificate. " +
+					"Can be set with the `KUBE_INSECURE` environment variable",
+				Optional: true,
+			},
+			"tls_server_name": schema.StringAttribute{
+				MarkdownDescription: "Server name used to verify the Kubernetes API server certificate. " +
+					"Can be set with the `KUBE_TLS_SERVER_NAME` environment variable",
+				Optional: true,
+			},
+			"proxy_url": schema.StringAttribute{
+				MarkdownDescription: "URL of the proxy used for requests to the Kubernetes API server. " +
+					"Can be set with the `KUBE_PROXY_URL` environment variable",
+				Optional: true,
+			},
+			"qps": schema.Float64Attribute{
+				MarkdownDescription: fmt.Sprintf("Maximum queries per second to the Kubernetes API server, "+
+					"defaults to %d. Can be set with the `KUBE_QPS` environment variable", DefaultClientQPS),
+				Optional: true,
+			},
+			"burst": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("Maximum burst of requests to the Kubernetes API server, "+
+					"defaults to %d. Can be set with the `KUBE_BURST` environment variable", DefaultClientBurst),
+				Optional: true,
+			},
+			"manifest_base_url": schema.StringAttribute{
+				MarkdownDescription: "Base URL of the OLM releases that manifests of versions which aren't bundled " +
+					"with the provider are downloaded from, defaults to the GitHub releases. " +
+					"The manifests are expected at `<manifest_base_url>/download/<version>/{crds,olm}.yaml`. " +
+					"Can be set with the `OLM_MANIFEST_BASE_URL` environment variable",
+				Optional: true,
+			},
+			"manifest_http_headers": schema.MapAttribute{
+				MarkdownDescription: "HTTP headers added to the manifest download requests",
+				ElementType:         types.StringType,
 				Optional:            true,
+				Sensitive:           true,
+			},
+			"github_token": schema.StringAttribute{
+				MarkdownDescription: "Token sent as bearer authorization with the requests to GitHub, which " +
+					"raises its rate limits. It's never sent to `manifest_base_url`. Can be set with the " +
+					"`OLM_GITHUB_TOKEN` environment variable",
+				Optional:  true,
+				Sensitive: true,
+			},
+			"manifest_ca_certificate": schema.StringAttribute{
+				MarkdownDescription: "PEM encoded CA bundle trusted for the manifest downloads " +
+					"in addition to the system roots. " +
+					"Can be set with the `OLM_MANIFEST_CA_CERT_DATA` environment variable",
+				Optional: true,
+			},
+			"manifest_proxy_url": schema.StringAttribute{
+				MarkdownDescription: "URL of the proxy used for the manifest downloads, " +
+					"defaults to the proxy from the `HTTPS_PROXY` environment variable. " +
+					"Can be set with the `OLM_MANIFEST_PROXY_URL` environment variable",
+				Optional: true,
+			},
+			"manifest_download_retries": schema.Int64Attribute{
+				MarkdownDescription: fmt.Sprintf("How many times a failed manifest download is retried "+
+					"with an exponential backoff, defaults to %d. "+
+					"Can be set with the `OLM_MANIFEST_DOWNLOAD_RETRIES` environment variable",
+					installer.DefaultDownloadRetries),
+				Optional: true,
+			},
+			"manifest_cache_dir": schema.StringAttribute{
+				MarkdownDescription: "Directory where verified manifest downloads are cached by their sha256 " +
+					"checksum, so refreshes don't download them again and work offline after the first apply. " +
+					"Defaults to `olm-manifests` in the `TF_PLUGIN_CACHE_DIR` plugin cache when it is set, " +
+					"otherwise to `terraform-provider-olm/manifests` in the user cache directory. " +
+					"An empty string disables the cache. " +
+					"Can be set with the `OLM_MANIFEST_CACHE_DIR` environment variable",
+				Optional: true,
+			},
+		},
+		Blocks: map[string]schema.Block{
+			"exec": schema.SingleNestedBlock{
+				MarkdownDescription: "Exec credential plugin used to fetch short-lived credentials, " +
+					"such as `aws eks get-token` or `gke-gcloud-auth-plugin`",
+				Attributes: map[string]schema.Attribute{
+					"api_version": schema.StringAttribute{
+						MarkdownDescription: "API version of the ExecCredential returned by the plugin, " +
+							"e.g. `client.authentication.k8s.io/v1beta1`",
+						Optional: true,
+					},
+					"command": schema.StringAttribute{
+						MarkdownDescription: "Command to execute",
+						Optional:            true,
+					},
+					"args": schema.ListAttribute{
+						MarkdownDescription: "Arguments passed to the command",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+					"env": schema.MapAttribute{
+						MarkdownDescription: "Environment variables set for the command",
+						ElementType:         types.StringType,
+						Optional:            true,
+					},
+				},
 			},
 		},
 	}
 }
 
 func (p *OLMProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	// The configuration may depend on resources that don't exist yet, e.g. the cluster itself.
+	// Configuring is deferred to the apply, the resources skip their plan time checks until then.
+	if !req.Config.Raw.IsFullyKnown() {
+		tflog.Debug(ctx, "Deferring provider configuration until its values are known")
+		p.config = nil
+		resp.ResourceData = p
+		resp.DataSourceData = p
+		return
+	}
+
 	var data OLMProviderModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
 	if resp.Diagnostics.HasError() {
@@ -91,67 +275,52 @@ func (p *OLMProvider) Configure(ctx context.Context, req provider.ConfigureReque
 	}
 
 	// Store the version and configuration for later client initialization
+	resp.Diagnostics.Append(data.applyEnvDefaults()...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	p.config = &data
 	resp.ResourceData = p
 	resp.DataSourceData = p
 
 }
 
-func (p *OLMProvider) getClient() (*installer.Client, error) {
-
-	// Return the client if it's already been initialized
-	if p.client != nil {
-		return p.client, nil
+func (p *OLMProvider) ValidateConfig(ctx context.Context, req provider.ValidateConfigRequest, resp *provider.ValidateConfigResponse) {
+	var data OLMProviderModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &data)...)
+	if resp.Diagnostics.HasError() {
+		return
 	}
 
-	var config *rest.Config
-	var err error
-
-	// Check if the configuration is sufficient to initialize the client
-	//if p.config.Kubeconfig.IsUnknown() || p.config.Kubeconfig.IsNull() {
-	//	return nil, fmt.Errorf("insufficient configuration for OLM client initialization")
-	//} else if p.config.Host.IsUnknown() || p.config.Host.IsNull() ||
-	//	p.config.CACertificate.IsUnknown() || p.config.CACertificate.IsNull() ||
-	//	p.config.ClientCertificate.IsUnknown() || p.config.ClientCertificate.IsNull() ||
-	//	p.config.ClientKey.IsUnknown() || p.config.ClientKey.IsNull() {
-	//
-	//	return nil, fmt.Errorf("insufficient configuration for OLM client initialization")
-	//
-	//}
-	// Check if the configuration is sufficient load the config into the config struct
-	if !p.config.Kubeconfig.IsUnknown() && !p.config.Kubeconfig.IsNull() {
-
-		// Use the raw kubeconfig string to build the config
-		kubeconfigBytes := []byte(p.config.Kubeconfig.ValueString())
-		config, err = clientcmd.RESTConfigFromKubeConfig(kubeconfigBytes)
-		if err != nil {
-			return nil, err
-		}
-	} else if !p.config.Host.IsUnknown() || !p.config.Host.IsNull() ||
-		!p.config.CACertificate.IsUnknown() || !p.config.CACertificate.IsNull() ||
-		!p.config.ClientCertificate.IsUnknown() || !p.config.ClientCertificate.IsNull() ||
-		!p.config.ClientKey.IsUnknown() || !p.config.ClientKey.IsNull() {
-		// Use the host, ca_certificate, client_certificate and client_key to create the client
-		config = &rest.Config{
-			Host: p.config.Host.ValueString(),
-			TLSClientConfig: rest.TLSClientConfig{
-				CertData: []byte(p.config.ClientCertificate.ValueString()),
-				KeyData:  []byte(p.config.ClientKey.ValueString()),
-				CAData:   []byte(p.config.CACertificate.ValueString()),
-			},
-		}
-	} else {
-		return nil, fmt.Errorf("fucked up: insufficient configuration for OLM client initialization")
+	resp.Diagnostics.Append(data.applyEnvDefaults()...)
+	resp.Diagnostics.Append(data.validate()...)
+	resp.Diagnostics.Append(data.validateDownload()...)
+}
 
+// getClient returns the client for the configured cluster. It's safe to call
+// from concurrently running resources, which all share the same client.
+func (p *OLMProvider) getClient() (*installer.Client, error) {
+	if p.config == nil {
+		return nil, errors.New("the provider is not configured, its configuration depends on values not known yet")
 	}
-
-	// Initialize the client
-	p.client, err = installer.ClientForConfig(config)
+	config, err := p.config.restConfig()
 	if err != nil {
 		return nil, err
 	}
+	return p.clients.get(config, p.config.downloadOptions())
+}
 
-	return p.client, err
+// getDownloadClient returns a client for the configured manifest downloads
+// that doesn't reach the cluster, which may not exist yet at plan time.
+func (p *OLMProvider) getDownloadClient() (*installer.Client, error) {
+	if p.config == nil {
+		return nil, errors.New("the provider is not configured")
+	}
+	c := &installer.Client{Client: &olmresourceclient.Client{Log: tflogLogger{}}}
+	if err := c.SetDownloadOptions(p.config.downloadOptions()); err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
 func (p *OLMProvider) Resources(ctx context.Context) []func() resource.Resource {
@@ -0,0 +1,57 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+)
+
+// objectValue returns a value of typ with the attributes in values, the
+// others null.
+func objectValue(typ tftypes.Type, values map[string]tftypes.Value) tftypes.Value {
+	object := typ.(tftypes.Object)
+	attrs := make(map[string]tftypes.Value, len(object.AttributeTypes))
+	for name, attrType := range object.AttributeTypes {
+		attrs[name] = tftypes.NewValue(attrType, nil)
+		if value, ok := values[name]; ok {
+			attrs[name] = value
+		}
+	}
+	return tftypes.NewValue(typ, attrs)
+}
+
+func TestConfigureDefersUnknownValues(t *testing.T) {
+	ctx := context.Background()
+	p := New("test")().(*OLMProvider)
+	schemaResp := &provider.SchemaResponse{}
+	p.Schema(ctx, provider.SchemaRequest{}, schemaResp)
+	typ := schemaResp.Schema.Type().TerraformType(ctx)
+	headersType := typ.(tftypes.Object).AttributeTypes["manifest_http_headers"]
+	config := tfsdk.Config{
+		Schema: schemaResp.Schema,
+		Raw: objectValue(typ, map[string]tftypes.Value{
+			"host":                  tftypes.NewValue(tftypes.String, "https://cluster.example.com"),
+			"manifest_http_headers": tftypes.NewValue(headersType, tftypes.UnknownValue),
+		}),
+	}
+
+	validateResp := &provider.ValidateConfigResponse{}
+	p.ValidateConfig(ctx, provider.ValidateConfigRequest{Config: config}, validateResp)
+	if validateResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected validation diagnostics: %v", validateResp.Diagnostics)
+	}
+	resp := &provider.ConfigureResponse{}
+	p.Configure(ctx, provider.ConfigureRequest{Config: config}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", resp.Diagnostics)
+	}
+	if resp.ResourceData != p || p.config != nil {
+		t.Errorf("configuration wasn't deferred: %+v", p.config)
+	}
+	if _, err := p.getClient(); err == nil {
+		t.Error("got a client for a deferred configuration")
+	}
+}
@@ -0,0 +1,141 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// TimeoutsModel is the timeouts block of a resource.
+type TimeoutsModel struct {
+	Create types.String `tfsdk:"create"`
+	Read   types.String `tfsdk:"read"`
+	Update types.String `tfsdk:"update"`
+	Delete types.String `tfsdk:"delete"`
+}
+
+// resourceTimeouts are the default timeouts of a resource.
+type resourceTimeouts struct {
+	Create, Read, Update, Delete time.Duration
+}
+
+// Default timeouts of the resources. Installing OLM waits for its CRDs and
+// deployments, installing an operator for its bundle to be unpacked. Reading
+// an operator waits for its CSV to succeed.
+var (
+	olmTimeouts = resourceTimeouts{Create: 10 * time.Minute, Read: 5 * time.Minute, Update: 10 * time.Minute,
+		Delete: 5 * time.Minute}
+	operatorTimeouts = resourceTimeouts{Create: 15 * time.Minute, Read: 5 * time.Minute, Update: 15 * time.Minute,
+		Delete: 5 * time.Minute}
+)
+
+// create returns ctx with the create timeout of m, or def when unset.
+func (m *TimeoutsModel) create(ctx context.Context, def resourceTimeouts) (context.Context, context.CancelFunc) {
+	var value types.String
+	if m != nil {
+		value = m.Create
+	}
+	return withTimeout(ctx, value, def.Create)
+}
+
+// read returns ctx with the read timeout of m, or def when unset.
+func (m *TimeoutsModel) read(ctx context.Context, def resourceTimeouts) (context.Context, context.CancelFunc) {
+	var value types.String
+	if m != nil {
+		value = m.Read
+	}
+	return withTimeout(ctx, value, def.Read)
+}
+
+// update returns ctx with the update timeout of m, or def when unset.
+func (m *TimeoutsModel) update(ctx context.Context, def resourceTimeouts) (context.Context, context.CancelFunc) {
+	var value types.String
+	if m != nil {
+		value = m.Update
+	}
+	return withTimeout(ctx, value, def.Update)
+}
+
+// delete returns ctx with the delete timeout of m, or def when unset.
+func (m *TimeoutsModel) delete(ctx context.Context, def resourceTimeouts) (context.Context, context.CancelFunc) {
+	var value types.String
+	if m != nil {
+		value = m.Delete
+	}
+	return withTimeout(ctx, value, def.Delete)
+}
+
+// withTimeout returns ctx with the timeout in value, expected to have passed
+// validate, or def when value is unset.
+func withTimeout(ctx context.Context, value types.String, def time.Duration) (context.Context, context.CancelFunc) {
+	timeout := def
+	if d, err := time.ParseDuration(value.ValueString()); err == nil && !value.IsNull() {
+		timeout = d
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// validate reports timeouts that aren't positive durations.
+func (m *TimeoutsModel) validate() (diags diag.Diagnostics) {
+	if m == nil {
+		return nil
+	}
+	for _, field := range []struct {
+		name  string
+		value types.String
+	}{
+		{"create", m.Create},
+		{"read", m.Read},
+		{"update", m.Update},
+		{"delete", m.Delete},
+	} {
+		if field.value.IsNull() || field.value.IsUnknown() {
+			continue
+		}
+		if d, err := time.ParseDuration(field.value.ValueString()); err != nil || d <= 0 {
+			diags.AddAttributeError(path.Root("timeouts").AtName(field.name), "Invalid timeout",
+				fmt.Sprintf("%q is not a positive duration such as \"30s\" or \"10m\"", field.value.ValueString()))
+		}
+	}
+	return diags
+}
+
+// timeoutsBlock returns the schema of the timeouts block of a resource with
+// the default timeouts def.
+func timeoutsBlock(def resourceTimeouts) schema.SingleNestedBlock {
+	attribute := func(operation string, d time.Duration) schema.StringAttribute {
+		return schema.StringAttribute{
+			MarkdownDescription: fmt.Sprintf("How long %s may take, e.g. `30s` or `10m`, defaults to `%s`",
+				operation, shortDuration(d)),
+			Optional: true,
+		}
+	}
+	return schema.SingleNestedBlock{
+		MarkdownDescription: "Timeouts of the operations, which wait for CRDs to be established, " +
+			"deployments to roll out, subscriptions to resolve and CSVs to succeed",
+		Attributes: map[string]schema.Attribute{
+			"create": attribute("creating", def.Create),
+			"read":   attribute("reading", def.Read),
+			"update": attribute("updating", def.Update),
+			"delete": attribute("deleting", def.Delete),
+		},
+	}
+}
+
+// shortDuration formats d without zero units, e.g. "10m" rather than "10m0s".
+func shortDuration(d time.Duration) string {
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
@@ -0,0 +1,41 @@
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestTimeouts(t *testing.T) {
+	var unset *TimeoutsModel
+	if diags := unset.validate(); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	ctx, cancel := unset.create(context.Background(), olmTimeouts)
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > olmTimeouts.Create {
+		t.Errorf("deadline = %v, want the default create timeout %s", deadline, olmTimeouts.Create)
+	}
+
+	ctx, cancel = unset.read(context.Background(), operatorTimeouts)
+	defer cancel()
+	if deadline, ok := ctx.Deadline(); !ok || time.Until(deadline) > operatorTimeouts.Read {
+		t.Errorf("deadline = %v, want the default read timeout %s", deadline, operatorTimeouts.Read)
+	}
+
+	m := &TimeoutsModel{Create: types.StringValue("30s"), Delete: types.StringValue("-1m"), Update: types.StringValue("soon")}
+	if diags := m.validate(); diags.ErrorsCount() != 2 {
+		t.Errorf("got %d errors, want 2 for the delete and update timeouts: %v", diags.ErrorsCount(), diags)
+	}
+	ctx, cancel = m.create(context.Background(), olmTimeouts)
+	defer cancel()
+	if deadline, _ := ctx.Deadline(); time.Until(deadline) > 30*time.Second {
+		t.Errorf("deadline = %v, want the configured 30s", deadline)
+	}
+
+	if got := shortDuration(olmTimeouts.Create); got != "10m" {
+		t.Errorf("shortDuration = %q, want 10m", got)
+	}
+}